@@ -44,16 +44,17 @@ func main() {
 		zap.String("openrouter_model", cfg.OpenRouter.Model),
 	)
 
-	// 初始化快取
-	cacheManager := cache.NewManager(cfg)
-	// 只在快取開啟但初始化失敗時才 Fatal
-	if cfg.Cache.Enabled && cacheManager == nil {
-		common.LogFatal("Failed to initialize cache manager")
+	// 初始化快取（依 CACHE_BACKEND 選擇記憶體或 Redis，停用時為 nil）
+	cacheManager, err := cache.New(cfg)
+	if err != nil {
+		common.LogFatal("Failed to initialize cache", zap.Error(err))
+	}
+	if cacheManager != nil {
+		defer cacheManager.Close()
 	}
-	defer cacheManager.Close()
 
 	// 設置路由
-	router, err := api.SetupRouter(cfg, cacheManager)
+	router, cleanup, err := api.SetupRouter(cfg, cacheManager)
 	if err != nil {
 		common.LogError("Failed to setup router", zap.Error(err))
 		os.Exit(1)
@@ -95,7 +96,12 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	err = srv.Shutdown(ctx)
+
+	// 停止請求隊列、關閉 AI 提供者與背景清理（快取於 main 結束時關閉）
+	cleanup()
+
+	if err != nil {
 		common.LogError("Server forced to shutdown",
 			zap.Error(err),
 		)
@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"recipe-generator/internal/core/ai/cache"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultListLimit 列出條目的預設筆數
+	defaultListLimit = 100
+	// maxListLimit 列出條目的筆數上限
+	maxListLimit = 1000
+)
+
+// CacheHandler 緩存管理處理程序
+type CacheHandler struct {
+	cache cache.Cache
+}
+
+// NewCacheHandler 創建緩存管理處理程序
+func NewCacheHandler(c cache.Cache) *CacheHandler {
+	return &CacheHandler{cache: c}
+}
+
+// Stats 獲取整體與各命名空間的命中、未命中與淘汰統計
+func (h *CacheHandler) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.GetStats())
+}
+
+// List 列出鍵以 prefix 參數開頭的條目（不含值），limit 參數預設 100、上限 1000
+func (h *CacheHandler) List(c *gin.Context) {
+	limit := defaultListLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxListLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.cache.List(c.Request.Context(), c.Query("prefix"), limit)
+	if err != nil {
+		common.LogError("列出快取條目失敗", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list cache entries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// Inspect 獲取單一條目與其值
+func (h *CacheHandler) Inspect(c *gin.Context) {
+	entry, err := h.cache.Inspect(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		if errors.Is(err, cache.ErrMiss) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cache entry not found or expired"})
+			return
+		}
+		common.LogError("讀取快取條目失敗", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read cache entry"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// Delete 刪除單一條目
+func (h *CacheHandler) Delete(c *gin.Context) {
+	key := c.Param("key")
+	ok, err := h.cache.Delete(c.Request.Context(), key)
+	if err != nil {
+		common.LogError("刪除快取條目失敗", zap.String("key", key), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cache entry"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cache entry not found or expired"})
+		return
+	}
+
+	common.LogInfo("快取條目已刪除",
+		zap.String("key", key),
+		zap.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusOK, gin.H{"deleted": 1})
+}
+
+// DeletePrefix 刪除鍵以 prefix 參數開頭的所有條目；清空全部請使用 Purge
+func (h *CacheHandler) DeletePrefix(c *gin.Context) {
+	prefix := c.Query("prefix")
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "prefix is required"})
+		return
+	}
+
+	n, err := h.cache.DeletePrefix(c.Request.Context(), prefix)
+	if err != nil {
+		common.LogError("依前綴刪除快取失敗", zap.String("prefix", prefix), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cache entries"})
+		return
+	}
+
+	common.LogInfo("已依前綴刪除快取",
+		zap.String("prefix", prefix),
+		zap.Int("deleted", n),
+		zap.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusOK, gin.H{"deleted": n})
+}
+
+// Purge 清空所有條目
+func (h *CacheHandler) Purge(c *gin.Context) {
+	n, err := h.cache.Purge(c.Request.Context())
+	if err != nil {
+		common.LogError("清空快取失敗", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge cache"})
+		return
+	}
+
+	common.LogInfo("快取已清空",
+		zap.Int("deleted", n),
+		zap.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusOK, gin.H{"deleted": n})
+}
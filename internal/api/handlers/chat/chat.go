@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"recipe-generator/internal/api/handlers"
+	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/chat"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateSessionRequest 建立對話請求，recipe / food_recognition / ingredient_recognition 擇一
+type CreateSessionRequest = chat.Grounding
+
+// CreateSessionResponse 建立對話響應
+type CreateSessionResponse struct {
+	SessionID   string    `json:"session_id"`
+	ContextType string    `json:"context_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MessageRequest 對話提問請求
+type MessageRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// Handler 烹飪助理對話處理程序
+type Handler struct {
+	chatService *chat.Service
+	debug       bool // 除錯模式下於回覆附上工具呼叫紀錄
+}
+
+// NewHandler 創建對話處理程序
+func NewHandler(chatService *chat.Service, debug bool) *Handler {
+	return &Handler{chatService: chatService, debug: debug}
+}
+
+// CreateSession 以食譜或辨識結果建立新對話
+func (h *Handler) CreateSession(c *gin.Context) {
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	session, err := h.chatService.CreateSession(req)
+	if err != nil {
+		if errors.Is(err, chat.ErrNoGrounding) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "One of recipe, food_recognition or ingredient_recognition is required",
+			})
+			return
+		}
+		common.LogError("建立對話失敗",
+			zap.Error(err),
+			zap.String("request_id", requestid.Get(c)),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateSessionResponse{
+		SessionID:   session.ID,
+		ContextType: session.ContextType,
+		ExpiresAt:   session.ExpiresAt,
+	})
+}
+
+// SendMessage 在對話中提問
+func (h *Handler) SendMessage(c *gin.Context) {
+	var req MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	sessionID := c.Param("id")
+	reply, err := h.chatService.Ask(c.Request.Context(), sessionID, req.Message)
+	if err != nil {
+		if errors.Is(err, chat.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found or expired"})
+			return
+		}
+		if handlers.WriteQueueFull(c.Writer, err) {
+			common.LogWarn("對話回覆被拒絕：AI 隊列已滿",
+				zap.String("request_id", requestid.Get(c)),
+				zap.String("session_id", sessionID),
+			)
+			return
+		}
+		common.LogError("對話回覆失敗",
+			zap.Error(err),
+			zap.String("request_id", requestid.Get(c)),
+			zap.String("session_id", sessionID),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Chat reply failed"})
+		return
+	}
+
+	if h.debug {
+		reply.ToolTrace = service.CallInfoFrom(c.Request.Context()).ToolCalls()
+	}
+	handlers.WriteAIHeaders(c.Request.Context(), c.Writer.Header())
+	c.JSON(http.StatusOK, reply)
+}
+
+// GetSession 獲取對話內容
+func (h *Handler) GetSession(c *gin.Context) {
+	session, err := h.chatService.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found or expired"})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// DeleteSession 結束對話
+func (h *Handler) DeleteSession(c *gin.Context) {
+	if err := h.chatService.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found or expired"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"recipe-generator/internal/core/ai/service"
+)
+
+// WriteAIHeaders 將 AI 呼叫資訊（快取狀態、實際回應的模型、prompt 模板、結構修正次數、使用量、工具呼叫）寫入響應頭
+func WriteAIHeaders(ctx context.Context, header http.Header) {
+	WriteCacheHeader(ctx, header)
+
+	info := service.CallInfoFrom(ctx)
+	if usage, calls := info.Usage(); calls > 0 {
+		header.Set("X-AI-Usage", service.FormatUsage(usage))
+	}
+	if models := info.Models(); len(models) > 0 {
+		header.Set("X-AI-Model", strings.Join(models, ","))
+	}
+	if templates := info.Templates(); len(templates) > 0 {
+		header.Set("X-AI-Prompt-Template", strings.Join(templates, ","))
+	}
+	if repairs := info.Repairs(); repairs > 0 {
+		header.Set("X-AI-Schema-Repairs", strconv.Itoa(repairs))
+	}
+	if calls := info.ToolCalls(); len(calls) > 0 {
+		names := make([]string, len(calls))
+		for i, call := range calls {
+			names[i] = call.Name
+		}
+		header.Set("X-AI-Tool-Calls", strings.Join(names, ","))
+	}
+}
+
+// WriteCacheHeader 將快取查詢結果（HIT、STALE 或 MISS）寫入 X-Cache 響應頭，未查詢快取時不寫入；
+// 串流響應在輸出第一個事件前呼叫，此時快取狀態已確定
+func WriteCacheHeader(ctx context.Context, header http.Header) {
+	if status := service.CallInfoFrom(ctx).CacheStatus(); len(status) > 0 {
+		header.Set("X-Cache", strings.Join(status, ","))
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"runtime"
 	"time"
 
+	"recipe-generator/internal/core/ai/fallback"
+	"recipe-generator/internal/core/ai/queue"
+	"recipe-generator/internal/core/ai/retry"
+	"recipe-generator/internal/core/ai/service"
 	"recipe-generator/internal/infrastructure/config"
 	"recipe-generator/internal/pkg/common"
 
@@ -14,19 +18,13 @@ import (
 
 // HealthResponse 健康檢查響應
 type HealthResponse struct {
-	Status    string                 `json:"status"`
-	Timestamp time.Time              `json:"timestamp"`
-	Version   string                 `json:"version"`
-	Runtime   map[string]interface{} `json:"runtime"`
-	Queue     *QueueStatus           `json:"queue,omitempty"`
-}
-
-// QueueStatus 隊列狀態
-type QueueStatus struct {
-	QueueLength    int `json:"queue_length"`
-	ProcessedCount int `json:"processed_count"`
-	MaxQueueSize   int `json:"max_queue_size"`
-	Workers        int `json:"workers"`
+	Status    string                   `json:"status"`
+	Timestamp time.Time                `json:"timestamp"`
+	Version   string                   `json:"version"`
+	Runtime   map[string]interface{}   `json:"runtime"`
+	Queue     *queue.Status            `json:"queue,omitempty"`
+	Breakers  []fallback.BreakerStatus `json:"breakers,omitempty"`
+	Retries   *retry.Stats             `json:"retries,omitempty"`
 }
 
 // HealthCheck 健康檢查處理器
@@ -58,7 +56,6 @@ func HealthCheck(c *gin.Context) {
 		})
 		return
 	}
-	_ = aiSvc // 若未使用，直接忽略
 
 	// 獲取運行時信息
 	var m runtime.MemStats
@@ -80,7 +77,12 @@ func HealthCheck(c *gin.Context) {
 		},
 	}
 
-	// 如果 AI 服務可用，這裡可擴充隊列狀態（暫不實作）
+	// AI 請求隊列狀態、各模型斷路器狀態與上游重試統計
+	if svc, ok := aiSvc.(*service.Service); ok {
+		response.Queue = svc.QueueStatus()
+		response.Breakers = svc.BreakerStatus()
+		response.Retries = svc.RetryStats()
+	}
 
 	// 記錄請求
 	common.LogInfo("Health check request",
@@ -91,23 +93,9 @@ func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// ReadinessCheck 就緒檢查處理器
-func ReadinessCheck(c *gin.Context) {
-	// TODO: 添加更多檢查
-	// - 數據庫連接
-	// - 外部服務依賴
-	// - 隊列狀態
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ready",
-	})
-}
-
-// LivenessCheck 存活檢查處理器
+// LivenessCheck 存活檢查處理器：只確認程序仍能處理請求，不檢查外部依賴，
+// 避免上游故障時所有 Pod 同時被重啟
 func LivenessCheck(c *gin.Context) {
-	// TODO: 添加更多檢查
-	// - 內存使用
-	// - CPU 使用
-	// - 線程數
 	c.JSON(http.StatusOK, gin.H{
 		"status": "alive",
 	})
@@ -0,0 +1,174 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/cache"
+	"recipe-generator/internal/core/ai/fallback"
+	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 單項檢查狀態
+const (
+	CheckOK       = "ok"       // 正常
+	CheckDegraded = "degraded" // 部分異常但仍可服務
+	CheckFail     = "fail"     // 無法服務，就緒檢查失敗
+	CheckSkipped  = "skipped"  // 不適用（如快取停用、回放模式）
+)
+
+// CheckResult 單項檢查結果
+type CheckResult struct {
+	Status    string     `json:"status"`
+	Message   string     `json:"message,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"` // 快取的探測結果實際檢查的時間
+}
+
+// ReadinessResponse 就緒檢查響應
+type ReadinessResponse struct {
+	Status    string                 `json:"status"` // ready 或 not_ready
+	Timestamp time.Time              `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Checker 就緒檢查器：上游提供者的探測結果快取 ProbeTTL，其餘檢查每次即時計算
+type Checker struct {
+	config       config.ReadinessConfig
+	aiService    *service.Service
+	cacheManager cache.Cache
+
+	mu       sync.Mutex
+	probed   CheckResult
+	probedAt time.Time
+}
+
+// NewChecker 創建就緒檢查器，cacheManager 為 nil 表示快取停用
+func NewChecker(cfg config.ReadinessConfig, aiService *service.Service, cacheManager cache.Cache) *Checker {
+	return &Checker{
+		config:       cfg,
+		aiService:    aiService,
+		cacheManager: cacheManager,
+	}
+}
+
+// Check 執行所有檢查，任一項為 fail 時不就緒
+func (ch *Checker) Check(ctx context.Context) ReadinessResponse {
+	response := ReadinessResponse{
+		Status:    "ready",
+		Timestamp: time.Now(),
+		Checks: map[string]CheckResult{
+			"provider": ch.checkProvider(ctx),
+			"cache":    ch.checkCache(ctx),
+			"queue":    ch.checkQueue(),
+			"breakers": ch.checkBreakers(),
+		},
+	}
+	for _, result := range response.Checks {
+		if result.Status == CheckFail {
+			response.Status = "not_ready"
+			break
+		}
+	}
+	return response
+}
+
+// checkProvider 探測上游連線、憑證與額度，結果快取 ProbeTTL 以免頻繁呼叫上游
+func (ch *Checker) checkProvider(ctx context.Context) CheckResult {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if !ch.probedAt.IsZero() && time.Since(ch.probedAt) < ch.config.ProbeTTL {
+		return ch.probed
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, ch.config.ProbeTimeout)
+	defer cancel()
+
+	now := time.Now()
+	result := CheckResult{Status: CheckOK, CheckedAt: &now}
+	if err := ch.aiService.Probe(probeCtx); err != nil {
+		if errors.Is(err, service.ErrProbeUnsupported) {
+			result = CheckResult{Status: CheckSkipped, Message: err.Error(), CheckedAt: &now}
+		} else {
+			result = CheckResult{Status: CheckFail, Message: err.Error(), CheckedAt: &now}
+			common.LogWarn("上游提供者探測失敗", zap.Error(err))
+		}
+	}
+
+	// 呼叫端取消時不快取結果，避免把未完成的探測當成上游故障
+	if ctx.Err() == nil {
+		ch.probed = result
+		ch.probedAt = now
+	}
+	return result
+}
+
+// checkCache 檢查快取後端
+func (ch *Checker) checkCache(ctx context.Context) CheckResult {
+	if ch.cacheManager == nil {
+		return CheckResult{Status: CheckSkipped, Message: "cache disabled"}
+	}
+	if err := ch.cacheManager.Ping(ctx); err != nil {
+		return CheckResult{Status: CheckFail, Message: err.Error()}
+	}
+	return CheckResult{Status: CheckOK}
+}
+
+// checkQueue 檢查 AI 請求隊列是否接近飽和
+func (ch *Checker) checkQueue() CheckResult {
+	status := ch.aiService.QueueStatus()
+	message := fmt.Sprintf("%d/%d queued", status.QueueLength, status.MaxQueueSize)
+	if status.MaxQueueSize > 0 && float64(status.QueueLength) >= ch.config.QueueThreshold*float64(status.MaxQueueSize) {
+		return CheckResult{Status: CheckFail, Message: message}
+	}
+	return CheckResult{Status: CheckOK, Message: message}
+}
+
+// checkBreakers 檢查各模型斷路器：全部熔斷時無法服務，部分熔斷時仍可改用其他模型
+func (ch *Checker) checkBreakers() CheckResult {
+	breakers := ch.aiService.BreakerStatus()
+	if len(breakers) == 0 {
+		return CheckResult{Status: CheckSkipped, Message: "no breakers"}
+	}
+
+	var open []string
+	for _, b := range breakers {
+		if b.State == fallback.StateOpen {
+			open = append(open, b.Model)
+		}
+	}
+	switch {
+	case len(open) == 0:
+		return CheckResult{Status: CheckOK}
+	case len(open) == len(breakers):
+		return CheckResult{Status: CheckFail, Message: "all models open: " + strings.Join(open, ", ")}
+	default:
+		return CheckResult{Status: CheckDegraded, Message: "open: " + strings.Join(open, ", ")}
+	}
+}
+
+// ReadinessCheck 就緒檢查處理器：檢查上游提供者、快取、隊列與斷路器，
+// 任一項失敗時回傳 503，讓負載平衡器停止導入流量
+func ReadinessCheck(checker *Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response := checker.Check(c.Request.Context())
+		if response.Status != "ready" {
+			common.LogWarn("Readiness check failed",
+				zap.Any("checks", response.Checks),
+			)
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"net/http"
+
+	"recipe-generator/internal/core/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Get 查詢非同步工作的狀態與結果；工作不存在或已超過保留期限時回傳 404
+func Get(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := manager.Get(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found or expired"})
+			return
+		}
+		c.JSON(http.StatusOK, job.View())
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"recipe-generator/internal/core/ai/queue"
+	"recipe-generator/internal/pkg/common"
+)
+
+// QueueFullMessage AI 請求隊列已滿時回傳的錯誤訊息
+const QueueFullMessage = "AI service is busy, please retry later"
+
+// RetryAfterSeconds 獲取隊列已滿錯誤的建議重試秒數，err 不是隊列已滿時 ok 為 false
+func RetryAfterSeconds(err error) (int, bool) {
+	var full *queue.FullError
+	if !errors.As(err, &full) {
+		return 0, false
+	}
+	return int(math.Ceil(full.RetryAfter.Seconds())), true
+}
+
+// WriteQueueFull err 為 AI 請求隊列已滿時回傳 503 與 Retry-After 並返回 true
+func WriteQueueFull(w http.ResponseWriter, err error) bool {
+	seconds, ok := RetryAfterSeconds(err)
+	if !ok {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	common.WriteErrorResponse(w, http.StatusServiceUnavailable, QueueFullMessage)
+	return true
+}
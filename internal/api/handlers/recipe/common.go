@@ -2,9 +2,29 @@ package recipe
 
 import (
 	"encoding/base64"
+	"net/http"
 	"strings"
+
+	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/locale"
+
+	"go.uber.org/zap"
 )
 
+// resolveLocale 依請求的 locale 欄位與 Accept-Language 決定輸出語系，並寫入 Content-Language 響應頭；
+// 不支援的語系依規則回退（例如 en-US → en、zh-HK → zh-TW，其餘使用預設語系）
+func resolveLocale(explicit, acceptLanguage string, header http.Header) string {
+	lang := locale.Resolve(explicit, acceptLanguage)
+	if explicit != "" && explicit != lang {
+		common.LogDebug("輸出語系已回退",
+			zap.String("requested", explicit),
+			zap.String("locale", lang),
+		)
+	}
+	header.Set("Content-Language", lang)
+	return lang
+}
+
 // getImageType 獲取圖片類型（用於日誌記錄）
 func getImageType(image string) string {
 	if image == "" {
@@ -3,6 +3,7 @@ package recipe
 import (
 	"net/http"
 
+	"recipe-generator/internal/api/handlers"
 	"recipe-generator/internal/core/ai/image"
 	recipeService "recipe-generator/internal/core/recipe"
 	"recipe-generator/internal/pkg/common"
@@ -18,12 +19,14 @@ import (
 type FoodRecognitionRequest struct {
 	Image           string `json:"image" binding:"required"`   // base64 encoded image 或 image URL
 	DescriptionHint string `json:"description_hint,omitempty"` // 可選，使用者對圖片的簡述
+	Locale          string `json:"locale,omitempty"`           // 可選，輸出語系（zh-TW、en、ja），未指定時依 Accept-Language
 }
 
 // FoodRecognitionResponse 圖片辨識食物回應
 // recognized_foods: [{name, description, possible_ingredients, possible_equipment}]
 type FoodRecognitionResponse struct {
 	RecognizedFoods []RecognizedFood `json:"recognized_foods"` // 辨識出的食物列表
+	Locale          string           `json:"locale"`           // 實際使用的輸出語系
 }
 
 type RecognizedFood struct {
@@ -83,6 +86,8 @@ func HandleFoodRecognition(foodService *recipeService.FoodService, imageService
 			return
 		}
 
+		lang := resolveLocale(req.Locale, c.GetHeader("Accept-Language"), c.Writer.Header())
+
 		// 處理圖片
 		processedImage, err := imageService.FormatImageData(req.Image)
 		if err != nil {
@@ -98,8 +103,15 @@ func HandleFoodRecognition(foodService *recipeService.FoodService, imageService
 		}
 
 		// 識別食物
-		foods, err := foodService.IdentifyFood(c.Request.Context(), processedImage, req.DescriptionHint)
+		foods, err := foodService.IdentifyFood(c.Request.Context(), processedImage, req.DescriptionHint, lang)
 		if err != nil {
+			// AI 請求隊列已滿，回傳 503
+			if handlers.WriteQueueFull(c.Writer, err) {
+				common.LogWarn("食物辨識請求被拒絕：AI 隊列已滿",
+					zap.String("request_id", requestID),
+				)
+				return
+			}
 			// 圖片格式錯誤，回傳 400
 			errStr := err.Error()
 			if errStr == "no choices in OpenRouter response" {
@@ -138,6 +150,7 @@ func HandleFoodRecognition(foodService *recipeService.FoodService, imageService
 
 		response := FoodRecognitionResponse{
 			RecognizedFoods: make([]RecognizedFood, len(foods.RecognizedFoods)),
+			Locale:          lang,
 		}
 
 		for i, food := range foods.RecognizedFoods {
@@ -164,6 +177,7 @@ func HandleFoodRecognition(foodService *recipeService.FoodService, imageService
 			zap.Int("foods_count", len(foods.RecognizedFoods)),
 		)
 
+		handlers.WriteAIHeaders(c.Request.Context(), c.Writer.Header())
 		c.JSON(http.StatusOK, response)
 	}
 }
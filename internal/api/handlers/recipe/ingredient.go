@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"recipe-generator/internal/api/handlers"
 	"recipe-generator/internal/core/ai/image"
 	"recipe-generator/internal/core/recipe"
 	"recipe-generator/internal/pkg/common"
@@ -16,6 +17,7 @@ import (
 type IngredientRecognitionRequest struct {
 	Image           string `json:"image" binding:"required"`
 	DescriptionHint string `json:"description_hint,omitempty"`
+	Locale          string `json:"locale,omitempty"` // 輸出語系（zh-TW、en、ja），未指定時依 Accept-Language
 }
 
 // IngredientRecognitionResponse 食材識別響應
@@ -23,6 +25,7 @@ type IngredientRecognitionResponse struct {
 	Ingredients []Ingredient `json:"ingredients"`
 	Equipment   []Equipment  `json:"equipment"`
 	Summary     string       `json:"summary"`
+	Locale      string       `json:"locale"` // 實際使用的輸出語系
 }
 
 // HandleIngredientRecognition 處理食材識別請求
@@ -56,6 +59,8 @@ func HandleIngredientRecognition(ingredientService *recipe.IngredientService, im
 			return
 		}
 
+		lang := resolveLocale(req.Locale, r.Header.Get("Accept-Language"), w.Header())
+
 		// 處理圖片
 		processedImage, err := imageService.FormatImageData(req.Image)
 		if err != nil {
@@ -67,8 +72,14 @@ func HandleIngredientRecognition(ingredientService *recipe.IngredientService, im
 		}
 
 		// 識別食材
-		result, err := ingredientService.IdentifyIngredient(r.Context(), processedImage)
+		result, err := ingredientService.IdentifyIngredient(r.Context(), processedImage, lang)
 		if err != nil {
+			// AI 請求隊列已滿，回傳 503
+			if handlers.WriteQueueFull(w, err) {
+				common.LogWarn("Ingredient recognition rejected: AI queue is full",
+					zap.String("request_id", requestID))
+				return
+			}
 			// 根據錯誤訊息內容判斷是否屬於用戶端錯誤
 			if strings.Contains(err.Error(), "image format") || strings.Contains(err.Error(), "base64") {
 				common.LogError("Invalid image format (service)",
@@ -90,6 +101,7 @@ func HandleIngredientRecognition(ingredientService *recipe.IngredientService, im
 			Ingredients: make([]Ingredient, len(result.Ingredients)),
 			Equipment:   make([]Equipment, len(result.Equipment)),
 			Summary:     result.Summary,
+			Locale:      lang,
 		}
 
 		// 轉換食材信息
@@ -115,6 +127,7 @@ func HandleIngredientRecognition(ingredientService *recipe.IngredientService, im
 		}
 
 		// 返回響應
+		handlers.WriteAIHeaders(r.Context(), w.Header())
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			common.LogError("Failed to encode response",
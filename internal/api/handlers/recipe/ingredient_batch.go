@@ -0,0 +1,207 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"recipe-generator/internal/api/handlers"
+	recipeService "recipe-generator/internal/core/recipe"
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// batchAction 批次路由的動作名稱：gin 不支援路徑中的字面冒號，
+// 因此 /ingredient:batch 註冊為參數 batch，其值包含冒號
+const batchAction = ":batch"
+
+// IngredientBatchRequest 批次食材識別請求
+type IngredientBatchRequest struct {
+	Images []string `json:"images" binding:"required"`
+	Locale string   `json:"locale,omitempty"` // 輸出語系（zh-TW、en、ja），未指定時依 Accept-Language
+}
+
+// IngredientBatchItem 單張圖片的辨識結果，成功時包含食材、設備與摘要，失敗時包含錯誤
+type IngredientBatchItem struct {
+	Index       int          `json:"index"`
+	Status      string       `json:"status"` // ok 或 error
+	Ingredients []Ingredient `json:"ingredients,omitempty"`
+	Equipment   []Equipment  `json:"equipment,omitempty"`
+	Summary     string       `json:"summary,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	RetryAfter  int          `json:"retry_after,omitempty"` // AI 隊列已滿時的建議重試秒數
+}
+
+// BatchIngredient 合併後的食材與其出現的圖片索引
+type BatchIngredient struct {
+	Ingredient
+	Images []int `json:"images"`
+}
+
+// BatchEquipment 合併後的設備與其出現的圖片索引
+type BatchEquipment struct {
+	Equipment
+	Images []int `json:"images"`
+}
+
+// IngredientBatchResponse 批次食材識別響應
+type IngredientBatchResponse struct {
+	Results     []IngredientBatchItem `json:"results"`
+	Ingredients []BatchIngredient     `json:"ingredients"` // 所有圖片合併去重後的食材
+	Equipment   []BatchEquipment      `json:"equipment"`   // 所有圖片合併去重後的設備
+	Succeeded   int                   `json:"succeeded"`
+	Failed      int                   `json:"failed"`
+	Locale      string                `json:"locale"`
+}
+
+// HandleIngredientBatch 處理 /recipe/ingredient:batch 批次食材識別 API
+func HandleIngredientBatch(ingredientService *recipeService.IngredientService, cfg config.BatchConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param("batch") != batchAction {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.New().String()
+			c.Header("X-Request-ID", requestID)
+		}
+
+		var req IngredientBatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.LogError("批次請求格式無效",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+		if len(req.Images) == 0 || len(req.Images) > cfg.MaxImages {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("images must contain 1 to %d items", cfg.MaxImages),
+			})
+			return
+		}
+
+		lang := resolveLocale(req.Locale, c.GetHeader("Accept-Language"), c.Writer.Header())
+
+		items := ingredientService.IdentifyIngredientBatch(c.Request.Context(), req.Images, lang, cfg.Concurrency)
+		ingredients, equipment := recipeService.MergeBatch(items)
+
+		response := IngredientBatchResponse{
+			Results:     make([]IngredientBatchItem, len(items)),
+			Ingredients: make([]BatchIngredient, len(ingredients)),
+			Equipment:   make([]BatchEquipment, len(equipment)),
+			Locale:      lang,
+		}
+		busy := 0
+		for i, item := range items {
+			if item.Err != nil {
+				response.Failed++
+				response.Results[i] = batchError(item, requestID)
+				if response.Results[i].RetryAfter > 0 {
+					busy++
+				}
+				continue
+			}
+			response.Succeeded++
+			result := IngredientBatchItem{
+				Index:       item.Index,
+				Status:      "ok",
+				Ingredients: make([]Ingredient, len(item.Result.Ingredients)),
+				Equipment:   make([]Equipment, len(item.Result.Equipment)),
+				Summary:     item.Result.Summary,
+			}
+			for j, ing := range item.Result.Ingredients {
+				result.Ingredients[j] = toIngredient(ing)
+			}
+			for j, equip := range item.Result.Equipment {
+				result.Equipment[j] = toEquipment(equip)
+			}
+			response.Results[i] = result
+		}
+		for i, ing := range ingredients {
+			response.Ingredients[i] = BatchIngredient{Ingredient: toIngredient(ing.Ingredient), Images: ing.Images}
+		}
+		for i, equip := range equipment {
+			response.Equipment[i] = BatchEquipment{Equipment: toEquipment(equip.Equipment), Images: equip.Images}
+		}
+
+		// 所有圖片皆因 AI 隊列已滿而失敗時整批回傳 503
+		if busy == len(items) {
+			for _, item := range items {
+				if handlers.WriteQueueFull(c.Writer, item.Err) {
+					break
+				}
+			}
+			return
+		}
+
+		common.LogInfo("批次食材辨識請求完成",
+			zap.String("request_id", requestID),
+			zap.Int("images", len(items)),
+			zap.Int("succeeded", response.Succeeded),
+			zap.Int("failed", response.Failed),
+			zap.Int("ingredients_count", len(response.Ingredients)),
+			zap.Int("equipment_count", len(response.Equipment)),
+		)
+
+		handlers.WriteAIHeaders(c.Request.Context(), c.Writer.Header())
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// batchError 將單張圖片的錯誤轉換為回應項目
+func batchError(item recipeService.BatchItem, requestID string) IngredientBatchItem {
+	result := IngredientBatchItem{Index: item.Index, Status: "error"}
+	if seconds, ok := handlers.RetryAfterSeconds(item.Err); ok {
+		result.Error = handlers.QueueFullMessage
+		result.RetryAfter = seconds
+		return result
+	}
+
+	errStr := item.Err.Error()
+	switch {
+	case errors.Is(item.Err, recipeService.ErrInvalidImage) || strings.Contains(errStr, "image format") || strings.Contains(errStr, "base64") || strings.Contains(errStr, "decode image"):
+		result.Error = "Invalid image format"
+	case errors.Is(item.Err, context.DeadlineExceeded) || errors.Is(item.Err, context.Canceled):
+		result.Error = "Request timeout"
+	default:
+		result.Error = "Failed to identify ingredients"
+	}
+	common.LogWarn("批次中的圖片辨識失敗",
+		zap.Error(item.Err),
+		zap.String("request_id", requestID),
+		zap.Int("index", item.Index),
+	)
+	return result
+}
+
+// toIngredient 將食材轉換為 API 響應格式
+func toIngredient(ing common.Ingredient) Ingredient {
+	return Ingredient{
+		Name:        ing.Name,
+		Type:        ing.Type,
+		Amount:      ing.Amount,
+		Unit:        ing.Unit,
+		Preparation: ing.Preparation,
+	}
+}
+
+// toEquipment 將設備轉換為 API 響應格式
+func toEquipment(equip common.Equipment) Equipment {
+	return Equipment{
+		Name:        equip.Name,
+		Type:        equip.Type,
+		Size:        equip.Size,
+		Material:    equip.Material,
+		PowerSource: equip.PowerSource,
+	}
+}
@@ -2,6 +2,7 @@ package recipe
 
 import (
 	"net/http"
+	"recipe-generator/internal/api/handlers"
 	recipeService "recipe-generator/internal/core/recipe"
 	"recipe-generator/internal/pkg/common"
 
@@ -21,6 +22,7 @@ type RecipeByNameRequest struct {
 		Doneness      string `json:"doneness"`               // 希望的熟度（如：全熟、三分熟）
 		ServingSize   string `json:"serving_size,omitempty"` // 份量（例如：2人份，可省略）
 	} `json:"preference" binding:"required"`
+	Locale string `json:"locale,omitempty"` // 輸出語系（zh-TW、en、ja），未指定時依 Accept-Language
 }
 
 // RecipeByNameResponse 詳細新手友善食譜
@@ -30,6 +32,7 @@ type RecipeByNameResponse struct {
 	Ingredients     []Ingredient `json:"ingredients"`
 	Equipment       []Equipment  `json:"equipment"`
 	Recipe          []RecipeStep `json:"recipe"`
+	Locale          string       `json:"locale"` // 實際使用的輸出語系
 }
 
 type RecipeStep struct {
@@ -60,6 +63,7 @@ type RecipeByIngredientsRequest struct {
 		DietaryRestrictions []string `json:"dietary_restrictions,omitempty"` // 過敏原或禁忌
 		ServingSize         string   `json:"serving_size,omitempty"`         // 份量（可省略）
 	} `json:"preference" binding:"required"`
+	Locale string `json:"locale,omitempty"` // 輸出語系（zh-TW、en、ja），未指定時依 Accept-Language
 }
 
 // Handler 食譜處理程序
@@ -99,6 +103,8 @@ func (h *Handler) HandleRecipeByName(c *gin.Context) {
 		return
 	}
 
+	lang := resolveLocale(req.Locale, c.GetHeader("Accept-Language"), c.Writer.Header())
+
 	preferences := common.RecipePreferences{
 		CookingMethod:       req.Preference.CookingMethod,
 		DietaryRestrictions: []string{req.Preference.Doneness},
@@ -113,8 +119,21 @@ func (h *Handler) HandleRecipeByName(c *gin.Context) {
 		})
 	}
 
-	recipe, err := h.recipeService.GenerateRecipe(c.Request.Context(), req.DishName, ingredients, preferences)
+	if wantsEventStream(c) {
+		h.streamRecipe(c, requestID, func(handler recipeService.RecipeStreamHandler) (*common.Recipe, error) {
+			return h.recipeService.GenerateRecipeStream(c.Request.Context(), req.DishName, ingredients, preferences, lang, handler)
+		})
+		return
+	}
+
+	recipe, err := h.recipeService.GenerateRecipe(c.Request.Context(), req.DishName, ingredients, preferences, lang)
 	if err != nil {
+		if handlers.WriteQueueFull(c.Writer, err) {
+			common.LogWarn("食譜生成請求被拒絕：AI 隊列已滿",
+				zap.String("request_id", requestID),
+			)
+			return
+		}
 		common.LogError("食譜生成失敗",
 			zap.Error(err),
 			zap.String("request_id", requestID),
@@ -123,77 +142,16 @@ func (h *Handler) HandleRecipeByName(c *gin.Context) {
 		return
 	}
 
-	response := RecipeByNameResponse{
-		DishName:        req.DishName,
-		DishDescription: recipe.DishDescription,
-		Ingredients:     make([]Ingredient, len(recipe.Ingredients)),
-		Equipment:       make([]Equipment, len(recipe.Equipment)),
-		Recipe:          make([]RecipeStep, len(recipe.Recipe)),
-	}
-
-	for i, ing := range recipe.Ingredients {
-		response.Ingredients[i] = Ingredient{
-			Name:        ing.Name,
-			Type:        ing.Type,
-			Amount:      ing.Amount,
-			Unit:        ing.Unit,
-			Preparation: ing.Preparation,
-		}
-	}
-
-	for i, equip := range recipe.Equipment {
-		response.Equipment[i] = Equipment{
-			Name:        equip.Name,
-			Type:        equip.Type,
-			Size:        equip.Size,
-			Material:    equip.Material,
-			PowerSource: equip.PowerSource,
-		}
-	}
-
-	for i, step := range recipe.Recipe {
-		// 轉換 actions
-		actions := make([]RecipeAction, len(step.Actions))
-		for j, act := range step.Actions {
-			actions[j] = RecipeAction{
-				Action:            act.Action,
-				ToolRequired:      act.ToolRequired,
-				MaterialRequired:  act.MaterialRequired,
-				TimeMinutes:       act.TimeMinutes,
-				InstructionDetail: act.InstructionDetail,
-			}
-		}
-		// 轉換 warnings
-		var warnings string
-		switch w := any(step.Warnings).(type) {
-		case string:
-			warnings = w
-		case *string:
-			if w != nil {
-				warnings = *w
-			} else {
-				warnings = ""
-			}
-		default:
-			warnings = ""
-		}
-		response.Recipe[i] = RecipeStep{
-			StepNumber:         step.StepNumber,
-			Title:              step.Title,
-			Description:        step.Description,
-			Actions:            actions,
-			EstimatedTotalTime: step.EstimatedTotalTime,
-			Temperature:        step.Temperature,
-			Warnings:           warnings,
-			Notes:              step.Notes,
-		}
-	}
+	response := toRecipeResponse(recipe)
+	response.DishName = req.DishName
+	response.Locale = lang
 
 	common.LogInfo("食譜生成成功",
 		zap.String("request_id", requestID),
 		zap.String("dish_name", req.DishName),
 	)
 
+	handlers.WriteAIHeaders(c.Request.Context(), c.Writer.Header())
 	c.JSON(http.StatusOK, response)
 }
 
@@ -226,6 +184,7 @@ func (h *Handler) HandleRecipeByIngredients(c *gin.Context) {
 			DietaryRestrictions: req.Preference.DietaryRestrictions,
 			ServingSize:         req.Preference.ServingSize,
 		},
+		Locale: resolveLocale(req.Locale, c.GetHeader("Accept-Language"), c.Writer.Header()),
 	}
 	for i, ing := range req.AvailableIngredients {
 		serviceReq.AvailableIngredients[i] = common.Ingredient{
@@ -247,8 +206,21 @@ func (h *Handler) HandleRecipeByIngredients(c *gin.Context) {
 	}
 	common.LogDebug("轉換後的 serviceReq", zap.String("request_id", requestID), zap.Any("serviceReq", serviceReq))
 
+	if wantsEventStream(c) {
+		h.streamRecipe(c, requestID, func(handler recipeService.RecipeStreamHandler) (*common.Recipe, error) {
+			return h.suggestionService.SuggestRecipesStream(c.Request.Context(), serviceReq, handler)
+		})
+		return
+	}
+
 	result, err := h.suggestionService.SuggestRecipes(c.Request.Context(), serviceReq)
 	if err != nil {
+		if handlers.WriteQueueFull(c.Writer, err) {
+			common.LogWarn("食譜推薦請求被拒絕：AI 隊列已滿",
+				zap.String("request_id", requestID),
+			)
+			return
+		}
 		common.LogError("食譜推薦失敗",
 			zap.Error(err),
 			zap.String("request_id", requestID),
@@ -257,16 +229,30 @@ func (h *Handler) HandleRecipeByIngredients(c *gin.Context) {
 		return
 	}
 
+	response := toRecipeResponse(result)
+	response.Locale = serviceReq.Locale
+
+	common.LogInfo("食譜推薦成功",
+		zap.String("request_id", requestID),
+		zap.String("dish_name", result.DishName),
+	)
+
+	handlers.WriteAIHeaders(c.Request.Context(), c.Writer.Header())
+	c.JSON(http.StatusOK, response)
+}
+
+// toRecipeResponse 將食譜轉換為 API 響應格式
+func toRecipeResponse(recipe *common.Recipe) RecipeByNameResponse {
 	response := RecipeByNameResponse{
-		DishName:        result.DishName,
-		DishDescription: result.DishDescription,
-		Ingredients:     make([]Ingredient, len(result.Ingredients)),
-		Equipment:       make([]Equipment, len(result.Equipment)),
-		Recipe:          make([]RecipeStep, len(result.Recipe)),
+		DishName:        recipe.DishName,
+		DishDescription: recipe.DishDescription,
+		Ingredients:     make([]Ingredient, len(recipe.Ingredients)),
+		Equipment:       make([]Equipment, len(recipe.Equipment)),
+		Recipe:          make([]RecipeStep, len(recipe.Recipe)),
 	}
 
-	for j, ing := range result.Ingredients {
-		response.Ingredients[j] = Ingredient{
+	for i, ing := range recipe.Ingredients {
+		response.Ingredients[i] = Ingredient{
 			Name:        ing.Name,
 			Type:        ing.Type,
 			Amount:      ing.Amount,
@@ -275,8 +261,8 @@ func (h *Handler) HandleRecipeByIngredients(c *gin.Context) {
 		}
 	}
 
-	for j, equip := range result.Equipment {
-		response.Equipment[j] = Equipment{
+	for i, equip := range recipe.Equipment {
+		response.Equipment[i] = Equipment{
 			Name:        equip.Name,
 			Type:        equip.Type,
 			Size:        equip.Size,
@@ -285,50 +271,36 @@ func (h *Handler) HandleRecipeByIngredients(c *gin.Context) {
 		}
 	}
 
-	for j, step := range result.Recipe {
-		// 轉換 actions
-		actions := make([]RecipeAction, len(step.Actions))
-		for k, act := range step.Actions {
-			actions[k] = RecipeAction{
-				Action:            act.Action,
-				ToolRequired:      act.ToolRequired,
-				MaterialRequired:  act.MaterialRequired,
-				TimeMinutes:       act.TimeMinutes,
-				InstructionDetail: act.InstructionDetail,
-			}
-		}
-		// 轉換 warnings
-		var warnings string
-		switch w := any(step.Warnings).(type) {
-		case string:
-			warnings = w
-		case *string:
-			if w != nil {
-				warnings = *w
-			} else {
-				warnings = ""
-			}
-		default:
-			warnings = ""
-		}
-		response.Recipe[j] = RecipeStep{
-			StepNumber:         step.StepNumber,
-			Title:              step.Title,
-			Description:        step.Description,
-			Actions:            actions,
-			EstimatedTotalTime: step.EstimatedTotalTime,
-			Temperature:        step.Temperature,
-			Warnings:           warnings,
-			Notes:              step.Notes,
-		}
+	for i, step := range recipe.Recipe {
+		response.Recipe[i] = toRecipeStep(step)
 	}
 
-	common.LogInfo("食譜推薦成功",
-		zap.String("request_id", requestID),
-		zap.String("dish_name", result.DishName),
-	)
+	return response
+}
 
-	c.JSON(http.StatusOK, response)
+// toRecipeStep 將食譜步驟轉換為 API 響應格式
+func toRecipeStep(step common.RecipeStep) RecipeStep {
+	// 轉換 actions
+	actions := make([]RecipeAction, len(step.Actions))
+	for j, act := range step.Actions {
+		actions[j] = RecipeAction{
+			Action:            act.Action,
+			ToolRequired:      act.ToolRequired,
+			MaterialRequired:  act.MaterialRequired,
+			TimeMinutes:       act.TimeMinutes,
+			InstructionDetail: act.InstructionDetail,
+		}
+	}
+	return RecipeStep{
+		StepNumber:         step.StepNumber,
+		Title:              step.Title,
+		Description:        step.Description,
+		Actions:            actions,
+		EstimatedTotalTime: step.EstimatedTotalTime,
+		Temperature:        step.Temperature,
+		Warnings:           step.Warnings,
+		Notes:              step.Notes,
+	}
 }
 
 // Ingredient 食材結構
@@ -0,0 +1,103 @@
+package recipe
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"recipe-generator/internal/api/handlers"
+	"recipe-generator/internal/core/ai/service"
+	recipeService "recipe-generator/internal/core/recipe"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SSE 事件名稱
+const (
+	eventDelta = "delta" // AI 原始增量內容
+	eventStep  = "step"  // 已完整解析的食譜步驟
+	eventUsage = "usage" // 本次請求的 token 使用量與費用
+	eventDone  = "done"  // 完整驗證後的食譜
+	eventError = "error" // 生成失敗
+)
+
+// wantsEventStream 判斷客戶端是否要求 Server-Sent Events 串流
+func wantsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// streamRecipe 以 Server-Sent Events 串流輸出食譜生成過程
+func (h *Handler) streamRecipe(c *gin.Context, requestID string, run func(recipeService.RecipeStreamHandler) (*common.Recipe, error)) {
+	ctx := c.Request.Context()
+
+	// 串流可能超過伺服器寫入超時，改以請求超時作為寫入期限
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+			common.LogDebug("無法設定串流寫入期限",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(event string, data any) error {
+		// 響應頭在第一個事件時送出
+		if !c.Writer.Written() {
+			handlers.WriteCacheHeader(ctx, c.Writer.Header())
+		}
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+		// 客戶端斷線時中止生成
+		return ctx.Err()
+	}
+
+	start := time.Now()
+	steps := 0
+	recipe, err := run(recipeService.RecipeStreamHandler{
+		OnDelta: func(delta string) error {
+			return send(eventDelta, gin.H{"content": delta})
+		},
+		OnStep: func(step common.RecipeStep) error {
+			steps++
+			return send(eventStep, toRecipeStep(step))
+		},
+	})
+	if err != nil {
+		common.LogError("食譜串流生成失敗",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+			zap.Int("steps_sent", steps),
+		)
+		// 隊列已滿：尚未輸出任何事件時回傳 503，否則改以事件回傳建議重試秒數
+		if !c.Writer.Written() && handlers.WriteQueueFull(c.Writer, err) {
+			return
+		}
+		if seconds, ok := handlers.RetryAfterSeconds(err); ok {
+			_ = send(eventError, gin.H{"error": handlers.QueueFullMessage, "retry_after": seconds})
+			return
+		}
+		_ = send(eventError, gin.H{"error": "Recipe generation failed"})
+		return
+	}
+
+	common.LogInfo("食譜串流生成成功",
+		zap.String("request_id", requestID),
+		zap.String("dish_name", recipe.DishName),
+		zap.Int("steps_sent", steps),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	// 串流標頭已送出，使用量改以事件回傳
+	if usage, calls := service.CallInfoFrom(ctx).Usage(); calls > 0 {
+		_ = send(eventUsage, usage)
+	}
+	_ = send(eventDone, recipe)
+}
@@ -0,0 +1,31 @@
+package usage
+
+import (
+	"net/http"
+
+	"recipe-generator/internal/core/ai/usage"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Report 使用量報表處理器，依客戶端與端點列出 token 使用量與費用
+func Report(tracker *usage.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, tracker.Report())
+	}
+}
+
+// Reset 重置使用量統計，回傳重置前的報表
+func Reset(tracker *usage.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := tracker.Report()
+		tracker.Reset()
+		common.LogInfo("使用量統計已重置",
+			zap.String("client_ip", c.ClientIP()),
+		)
+
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminAuth 管理 API 驗證中間件，要求 Authorization: Bearer <ADMIN_TOKEN>
+func AdminAuth(token string) gin.HandlerFunc {
+	expected := []byte(token)
+
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		// 以固定時間比較，避免依回應時間逐字猜出 token
+		if !ok || subtle.ConstantTimeCompare([]byte(got), expected) != 1 {
+			common.LogWarn("管理 API 驗證失敗",
+				zap.String("ip", c.ClientIP()),
+				zap.String("path", c.Request.URL.Path),
+			)
+
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
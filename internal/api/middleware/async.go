@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"recipe-generator/internal/core/ai/queue"
+	"recipe-generator/internal/core/jobs"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CallbackURLHeader 非同步工作完成時接收 webhook 回呼的網址
+const CallbackURLHeader = "X-Callback-URL"
+
+// asyncJobKey 非同步工作完成通知的 context key，供 UsageTracking 延後記錄使用量
+const asyncJobKey = "async_job_done"
+
+// Async 非同步工作中間件：請求帶有 Prefer: respond-async 時，改為建立工作並立即回傳 202，
+// 在背景以與請求脫鉤的 context 執行原本的 handler，結果可由 statusPath + 工作 ID 查詢。
+// 未指定 X-Request-Priority 時工作以 background 優先級呼叫 AI；串流模式不適用，一律回傳 JSON
+func Async(manager *jobs.Manager, engine *gin.Engine, statusPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !prefersAsync(c.GetHeader("Prefer")) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":    "Request body too large",
+					"max_size": maxBytesErr.Limit,
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+
+		requestID := requestid.Get(c)
+		ctx := c.Request.Context()
+		if c.GetHeader(PriorityHeader) == "" {
+			ctx = queue.WithPriority(ctx, queue.PriorityBackground)
+		}
+
+		// 複製 handler 所需的請求內容，原本的 gin.Context 在回應後會被重複使用
+		req := c.Request.Clone(ctx)
+		req.Header.Del("Prefer")
+		req.Header.Del(CallbackURLHeader)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Request-ID", requestID)
+		handler := c.Handler()
+		params := append(gin.Params(nil), c.Params...)
+		keys := make(map[string]any, len(c.Keys))
+		for k, v := range c.Keys {
+			keys[k] = v
+		}
+
+		done := make(chan struct{})
+		job, err := manager.Submit(ctx, jobs.Spec{
+			Endpoint:    c.Request.Method + " " + c.FullPath(),
+			RequestID:   requestID,
+			CallbackURL: c.GetHeader(CallbackURLHeader),
+		}, func(jobCtx context.Context) jobs.Result {
+			defer close(done)
+
+			rec := &jobRecorder{header: make(http.Header)}
+			jc := gin.CreateTestContextOnly(rec, engine)
+			jc.Request = req.WithContext(jobCtx)
+			jc.Request.Body = io.NopCloser(bytes.NewReader(body))
+			jc.Params = params
+			jc.Keys = keys
+			handler(jc)
+
+			return jobs.Result{StatusCode: rec.statusCode(), Body: rec.body.Bytes()}
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, jobs.ErrInvalidCallback), errors.Is(err, jobs.ErrCallbackForbidden), errors.Is(err, jobs.ErrCallbackDisabled):
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, jobs.ErrTooManyJobs):
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Too many pending jobs"})
+			default:
+				common.LogError("建立非同步工作失敗", zap.Error(err), zap.String("request_id", requestID))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
+			}
+			return
+		}
+		c.Set(asyncJobKey, (<-chan struct{})(done))
+
+		c.Header("Location", statusPath+job.ID)
+		c.Header("Preference-Applied", "respond-async")
+		c.AbortWithStatusJSON(http.StatusAccepted, job.View())
+	}
+}
+
+// prefersAsync 檢查 Prefer 請求頭是否包含 respond-async
+func prefersAsync(prefer string) bool {
+	for _, pref := range strings.FieldsFunc(prefer, func(r rune) bool { return r == ',' || r == ';' }) {
+		if strings.EqualFold(strings.TrimSpace(pref), "respond-async") {
+			return true
+		}
+	}
+	return false
+}
+
+// jobRecorder 記錄非同步工作中 handler 寫出的狀態碼與內容
+type jobRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *jobRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *jobRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *jobRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+// Flush 無需輸出，僅滿足 http.Flusher
+func (r *jobRecorder) Flush() {}
+
+// statusCode 獲取 handler 回傳的狀態碼，未寫出任何內容時視為 200
+func (r *jobRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
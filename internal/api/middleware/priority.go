@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"recipe-generator/internal/core/ai/queue"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PriorityHeader 指定 AI 請求隊列優先級的請求頭：interactive、normal、background
+const PriorityHeader = "X-Request-Priority"
+
+// Priority 依 PriorityHeader 設定請求在 AI 隊列中的優先級，未提供或無法辨識時為 normal
+func Priority() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(PriorityHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		priority, ok := queue.ParsePriority(value)
+		if !ok {
+			common.LogDebug("Unknown request priority",
+				zap.String("priority", value),
+				zap.String("path", c.Request.URL.Path),
+			)
+		}
+		c.Request = c.Request.WithContext(queue.WithPriority(c.Request.Context(), priority))
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/ai/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIDHeader 識別 API 客戶端的請求頭，未提供時以客戶端 IP 代替
+const ClientIDHeader = "X-Client-ID"
+
+// UsageTracking 使用量統計中間件，請求結束後依客戶端與端點彙總本次 AI 使用量；
+// 非同步工作於完成時彙總。需註冊在附加 CallInfo 的中間件之後、Async 之前
+func UsageTracking(tracker *usage.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// 未匹配的路由不計入
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			return
+		}
+
+		client := c.GetHeader(ClientIDHeader)
+		if client == "" {
+			client = c.ClientIP()
+		}
+
+		route := c.Request.Method + " " + endpoint
+		info := service.CallInfoFrom(c.Request.Context())
+
+		// 非同步工作在回應後才呼叫 AI，完成時再記錄
+		if v, ok := c.Get(asyncJobKey); ok {
+			done := v.(<-chan struct{})
+			go func() {
+				<-done
+				u, calls := info.BilledUsage()
+				tracker.Record(client, route, calls, u)
+			}()
+			return
+		}
+
+		u, calls := info.BilledUsage()
+		tracker.Record(client, route, calls, u)
+	}
+}
@@ -4,12 +4,20 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	adminHandler "recipe-generator/internal/api/handlers/admin"
+	chatHandler "recipe-generator/internal/api/handlers/chat"
 	"recipe-generator/internal/api/handlers/health"
+	jobsHandler "recipe-generator/internal/api/handlers/jobs"
 	recipeHandler "recipe-generator/internal/api/handlers/recipe"
+	usageHandler "recipe-generator/internal/api/handlers/usage"
 	"recipe-generator/internal/api/middleware"
 	"recipe-generator/internal/core/ai/cache"
 	"recipe-generator/internal/core/ai/image"
 	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/ai/usage"
+	"recipe-generator/internal/core/chat"
+	"recipe-generator/internal/core/jobs"
+	"recipe-generator/internal/core/prompt"
 	recipeService "recipe-generator/internal/core/recipe"
 	"recipe-generator/internal/infrastructure/config"
 	"recipe-generator/internal/pkg/common"
@@ -28,8 +36,9 @@ const (
 	maxBodySize = 10 << 20
 )
 
-// SetupRouter 設置路由
-func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Engine, error) {
+// SetupRouter 設置路由，並返回關閉時釋放服務資源（請求隊列、AI 提供者、背景清理）的函式，
+// 應在 HTTP 服務器停止後呼叫
+func SetupRouter(cfg *config.Config, cacheManager cache.Cache) (*gin.Engine, func(), error) {
 	common.LogInfo("Starting router setup",
 		zap.Bool("debug_mode", cfg.App.Debug),
 		zap.String("version", cfg.App.Version),
@@ -47,15 +56,14 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 	// 註冊基礎中間件
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
-	router.Use(middleware.Deduplication(cfg))
 	router.Use(requestid.New()) // 自動生成請求 ID
 
 	// CORS 設置
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", middleware.ClientIDHeader, middleware.PriorityHeader, "Prefer", middleware.CallbackURLHeader},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "X-AI-Model", "X-AI-Schema-Repairs", "X-AI-Usage", "X-AI-Tool-Calls", "X-AI-Prompt-Template", "X-Cache", "Retry-After", "Location", "Preference-Applied"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -63,9 +71,13 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 	// 請求體大小限制
 	router.Use(middleware.BodySizeLimit(maxBodySize))
 
+	// AI 請求隊列優先級
+	router.Use(middleware.Priority())
+
 	common.LogInfo("Initializing services",
 		zap.Bool("cache_enabled", cfg.Cache.Enabled),
 		zap.Int("queue_workers", cfg.Queue.Workers),
+		zap.Int("queue_max_size", cfg.Queue.MaxSize),
 		zap.String("model", cfg.OpenRouter.Model),
 		zap.Duration("timeout", timeoutDuration),
 	)
@@ -74,27 +86,38 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 	aiService, err := service.NewService(cfg, cacheManager)
 	if err != nil || aiService == nil {
 		common.LogError("Failed to initialize AI service", zap.Error(err))
-		return nil, fmt.Errorf("failed to initialize AI service: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize AI service: %w", err)
 	}
 
 	// 初始化圖片服務
 	imageService := image.NewProcessor(1200) // 最大尺寸 1200px
 	if imageService == nil {
 		common.LogError("Failed to initialize image service")
-		return nil, fmt.Errorf("failed to initialize image service")
+		return nil, nil, fmt.Errorf("failed to initialize image service")
+	}
+
+	// 載入 prompt 模板
+	pinned, err := prompt.ParseVersions(cfg.Prompt.Versions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid prompt versions: %w", err)
+	}
+	prompts, err := prompt.NewRegistry(cfg.Prompt.Dir, pinned)
+	if err != nil {
+		common.LogError("Failed to load prompt templates", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to load prompt templates: %w", err)
 	}
 
 	// 初始化食材識別服務
-	ingredientSvc := recipeService.NewIngredientService(aiService, cacheManager, imageService)
+	ingredientSvc := recipeService.NewIngredientService(aiService, cacheManager, imageService, prompts)
 	if ingredientSvc == nil {
 		common.LogError("Failed to initialize ingredient service")
-		return nil, fmt.Errorf("failed to initialize ingredient service")
+		return nil, nil, fmt.Errorf("failed to initialize ingredient service")
 	}
 
 	// 初始化食譜服務
-	foodSvc := recipeService.NewFoodService(aiService, cacheManager)
-	recipeSvc := recipeService.NewRecipeService(aiService, cacheManager)
-	suggestionSvc := recipeService.NewSuggestionService(aiService, cacheManager)
+	foodSvc := recipeService.NewFoodService(aiService, cacheManager, prompts)
+	recipeSvc := recipeService.NewRecipeService(aiService, cacheManager, prompts)
+	suggestionSvc := recipeService.NewSuggestionService(aiService, cacheManager, prompts)
 
 	if foodSvc == nil || recipeSvc == nil || suggestionSvc == nil {
 		common.LogError("Failed to initialize recipe services: service returned nil",
@@ -102,9 +125,18 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 			zap.Bool("cache_manager_initialized", cacheManager != nil),
 			zap.String("environment", cfg.App.Env),
 		)
-		return nil, fmt.Errorf("failed to initialize recipe services: service returned nil")
+		return nil, nil, fmt.Errorf("failed to initialize recipe services: service returned nil")
 	}
 
+	// 初始化烹飪助理對話服務
+	chatSvc := chat.NewService(aiService, cfg.Chat)
+
+	// 使用量統計（依客戶端與端點彙總）
+	usageTracker := usage.NewTracker()
+
+	// 非同步工作（Prefer: respond-async）
+	jobManager := jobs.NewManager(cfg.Jobs)
+
 	common.LogInfo("Recipe services initialized successfully",
 		zap.Bool("ai_service_initialized", aiService != nil),
 		zap.Bool("cache_manager_initialized", cacheManager != nil),
@@ -117,8 +149,10 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeoutDuration)
 		defer cancel()
 
-		// 創建新的請求上下文
-		req := c.Request.WithContext(ctx)
+		// 創建新的請求上下文，並附加 AI 呼叫資訊供 handler 寫入響應頭
+		reqCtx, info := service.WithCallInfo(ctx)
+		info.SetRequestID(requestid.Get(c))
+		req := c.Request.WithContext(reqCtx)
 		c.Request = req
 
 		// 設置配置
@@ -155,6 +189,11 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 				zap.String("request_id", c.GetHeader("X-Request-ID")),
 				zap.Duration("timeout", timeoutDuration),
 			)
+			// 串流響應已送出標頭，無法再改寫為 JSON 錯誤
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
 			c.JSON(http.StatusGatewayTimeout, gin.H{
 				"error": "Request timeout",
 				"code":  "REQUEST_TIMEOUT",
@@ -169,14 +208,25 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 
 	// 健康檢查路由
 	router.GET("/health", health.HealthCheck)
-	router.GET("/ready", health.ReadinessCheck)
+	router.GET("/ready", health.ReadinessCheck(health.NewChecker(cfg.Readiness, aiService, cacheManager)))
 	router.GET("/live", health.LivenessCheck)
 
 	// API 路由組
 	api := router.Group("/api/v1")
+	if cfg.RateLimit.Enabled {
+		api.Use(middleware.RateLimit(cfg.RateLimit.Requests, cfg.RateLimit.Window))
+	}
 	{
-		// 註冊食譜相關路由
-		recipeGroup := api.Group("/recipe")
+		// 非同步工作查詢
+		api.GET("/jobs/:id", jobsHandler.Get(jobManager))
+
+		// 註冊食譜相關路由（統計各客戶端的 AI 使用量，支援非同步工作）；
+		// 啟用 AI_COALESCE 時重複的請求改由 AI 服務合併，不再以 429 拒絕
+		recipeGroup := api.Group("/recipe", middleware.UsageTracking(usageTracker))
+		if !cfg.AI.Coalesce {
+			recipeGroup.Use(middleware.Deduplication(cfg))
+		}
+		recipeGroup.Use(middleware.Async(jobManager, router, "/api/v1/jobs/"))
 		{
 			// 食物識別
 			recipeGroup.POST("/food", recipeHandler.HandleFoodRecognition(foodSvc, imageService))
@@ -186,6 +236,9 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 				recipeHandler.HandleIngredientRecognition(ingredientSvc, imageService)(c.Writer, c.Request)
 			})
 
+			// 批次食材識別（/ingredient:batch，batch 參數的值為 ":batch"）
+			recipeGroup.POST("/ingredient:batch", recipeHandler.HandleIngredientBatch(ingredientSvc, cfg.Batch))
+
 			// 使用食材名稱生成食譜
 			recipeGroup.POST("/generate", func(c *gin.Context) {
 				handler := recipeHandler.NewHandler(recipeSvc, suggestionSvc)
@@ -198,6 +251,36 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 				handler.HandleRecipeByIngredients(c)
 			})
 		}
+
+		// 註冊烹飪助理對話路由
+		chatGroup := api.Group("/chat", middleware.Deduplication(cfg), middleware.UsageTracking(usageTracker))
+		{
+			handler := chatHandler.NewHandler(chatSvc, cfg.App.Debug)
+			chatGroup.POST("/sessions", handler.CreateSession)
+			chatGroup.GET("/sessions/:id", handler.GetSession)
+			chatGroup.POST("/sessions/:id/messages", handler.SendMessage)
+			chatGroup.DELETE("/sessions/:id", handler.DeleteSession)
+		}
+
+		// 註冊管理路由（需設定 ADMIN_TOKEN）
+		if cfg.Admin.Token != "" {
+			adminGroup := api.Group("/admin", middleware.AdminAuth(cfg.Admin.Token))
+
+			// 使用量報表（含各客戶端識別），重置需使用 DELETE
+			adminGroup.GET("/usage", usageHandler.Report(usageTracker))
+			adminGroup.DELETE("/usage", usageHandler.Reset(usageTracker))
+
+			// 快取管理（需啟用快取）
+			if cacheManager != nil {
+				handler := adminHandler.NewCacheHandler(cacheManager)
+				adminGroup.GET("/cache/stats", handler.Stats)
+				adminGroup.GET("/cache/entries", handler.List)
+				adminGroup.GET("/cache/entries/:key", handler.Inspect)
+				adminGroup.DELETE("/cache/entries/:key", handler.Delete)
+				adminGroup.DELETE("/cache/entries", handler.DeletePrefix)
+				adminGroup.DELETE("/cache", handler.Purge)
+			}
+		}
 	}
 
 	common.LogInfo("Router setup completed successfully",
@@ -211,5 +294,12 @@ func SetupRouter(cfg *config.Config, cacheManager *cache.CacheManager) (*gin.Eng
 		zap.Int64("max_body_size", maxBodySize),
 	)
 
-	return router, nil
+	cleanup := func() {
+		jobManager.Close()
+		chatSvc.Close()
+		if err := aiService.Close(); err != nil {
+			common.LogError("Failed to close AI service", zap.Error(err))
+		}
+	}
+	return router, cleanup, nil
 }
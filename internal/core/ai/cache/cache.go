@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"recipe-generator/internal/infrastructure/config"
+)
+
+// 緩存後端
+const (
+	BackendMemory = "memory" // 單一程序內的記憶體緩存
+	BackendRedis  = "redis"  // 多個副本共用的 Redis 緩存
+	BackendTiered = "tiered" // 程序內 LRU 在前、Redis 在後的分層緩存
+	BackendDisk   = "disk"   // 儲存於本機檔案、重啟後保留的緩存
+)
+
+// Cache AI 回應緩存介面，鍵由命名空間、prompt 與圖片數據的哈希組成
+type Cache interface {
+	// Get 獲取緩存值，未命中時返回錯誤；stale 表示值已超過 CACHE_TTL、仍在 CACHE_STALE_TTL 內
+	Get(ctx context.Context, prompt, imageData string) (value string, stale bool, err error)
+
+	// Set 設置緩存值，CACHE_TTL 內為新鮮值，之後再保留 CACHE_STALE_TTL
+	Set(ctx context.Context, prompt, imageData, value string) error
+
+	// Ping 檢查緩存後端是否可用
+	Ping(ctx context.Context) error
+
+	// GetStats 獲取緩存統計信息
+	GetStats() map[string]interface{}
+
+	// List 列出鍵以 prefix 開頭的條目（不含值），limit 大於 0 時最多返回 limit 筆
+	List(ctx context.Context, prefix string, limit int) ([]Entry, error)
+
+	// Inspect 獲取單一條目與其值，不存在時返回 ErrMiss
+	Inspect(ctx context.Context, key string) (*Entry, error)
+
+	// Delete 刪除條目，返回條目是否存在
+	Delete(ctx context.Context, key string) (bool, error)
+
+	// DeletePrefix 刪除鍵以 prefix 開頭的所有條目，返回刪除數量
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+
+	// Purge 清空所有條目，返回刪除數量
+	Purge(ctx context.Context) (int, error)
+
+	// Close 關閉緩存
+	Close() error
+}
+
+var (
+	_ Cache = (*CacheManager)(nil)
+	_ Cache = (*RedisCache)(nil)
+	_ Cache = (*TieredCache)(nil)
+	_ Cache = (*DiskCache)(nil)
+)
+
+// New 依 CACHE_BACKEND 創建緩存，快取停用時返回 nil
+func New(cfg *config.Config) (Cache, error) {
+	if !cfg.Cache.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Cache.Backend {
+	case BackendMemory:
+		return NewManager(cfg), nil
+	case BackendRedis:
+		c, err := NewRedis(cfg.Cache)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	case BackendTiered:
+		c, err := NewTiered(cfg.Cache)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	case BackendDisk:
+		c, err := NewDisk(cfg.Cache)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unsupported cache backend: %s", cfg.Cache.Backend)
+	}
+}
+
+// lifetime 條目的保存時間：新鮮的 CACHE_TTL 加上可回傳舊值的 CACHE_STALE_TTL
+func lifetime(cfg config.CacheConfig) time.Duration {
+	return cfg.TTL + cfg.StaleTTL
+}
+
+// staleAt 依條目的到期時間推算超過 CACHE_TTL 的時間
+func staleAt(cfg config.CacheConfig, expiresAt time.Time) time.Time {
+	return expiresAt.Add(-cfg.StaleTTL)
+}
+
+// isStale 判斷條目是否已超過 CACHE_TTL
+func isStale(cfg config.CacheConfig, expiresAt, now time.Time) bool {
+	return !now.Before(staleAt(cfg, expiresAt))
+}
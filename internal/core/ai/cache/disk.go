@@ -0,0 +1,595 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// bbolt bucket 名稱
+var (
+	entriesBucket = []byte("entries") // 緩存鍵 → 到期時間（8 bytes）+ 值
+	expiryBucket  = []byte("expiry")  // 到期時間（8 bytes）+ 緩存鍵 → 空值，依到期時間排序以便清理與淘汰
+	metaBucket    = []byte("meta")    // 緩存鍵 → 條目資訊（JSON），不計入容量
+)
+
+// DiskCache 以 bbolt 儲存於本機檔案的緩存，重啟後保留內容，適合不使用 Redis 的單機部署
+type DiskCache struct {
+	mu      sync.RWMutex // 保護 db 與 openErr，重寫檔案時需獨佔
+	db      *bolt.DB
+	openErr error // 重寫後重新開啟檔案失敗的錯誤，不為 nil 時後端不可用
+	config  config.CacheConfig
+	path    string
+
+	bytes     atomic.Int64 // 目前所有條目（鍵 + 值）的大小
+	entries   atomic.Int64
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	errors    atomic.Int64
+	ns        namespaceStats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDisk 開啟（或建立）緩存檔案，載入既有條目並清除已過期的條目
+func NewDisk(cfg config.CacheConfig) (*DiskCache, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Disk.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	db, err := openDisk(cfg.Disk.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{
+		db:     db,
+		config: cfg,
+		path:   cfg.Disk.Path,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := d.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	expired, evicted, err := d.sweep()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	go d.startCompaction()
+
+	common.LogInfo("磁碟快取已載入",
+		zap.String("path", d.path),
+		zap.Int64("entries", d.entries.Load()),
+		zap.Int64("bytes", d.bytes.Load()),
+		zap.Int64("max_bytes", cfg.Disk.MaxBytes),
+		zap.Int("expired", expired),
+		zap.Int("evicted", evicted),
+	)
+	return d, nil
+}
+
+// openDisk 開啟 bbolt 檔案並建立所需的 bucket；檔案被其他程序鎖定時不無限等待
+func openDisk(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disk cache %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, expiryBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize disk cache %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Get 獲取未過期的緩存值與是否已超過 CACHE_TTL，過期條目留待定期清理
+func (d *DiskCache) Get(ctx context.Context, prompt, imageData string) (string, bool, error) {
+	key := []byte(generateKey(namespaceFrom(ctx), prompt, imageData))
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now()
+	var value string
+	var expiresAt time.Time
+	var found bool
+	err := d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(entriesBucket).Get(key)
+		if len(data) < 8 || now.UnixNano() >= decodeTime(data) {
+			return nil
+		}
+		value, expiresAt, found = string(data[8:]), time.Unix(0, decodeTime(data)), true
+		return nil
+	})
+	if err != nil {
+		d.errors.Add(1)
+		common.LogWarn("讀取磁碟快取失敗", zap.String("鍵", string(key)), zap.Error(err))
+		return "", false, fmt.Errorf("failed to get cache: %w", err)
+	}
+	if !found {
+		d.misses.Add(1)
+		d.ns.miss(string(key))
+		common.LogInfo("快取未命中", zap.String("鍵", string(key)))
+		return "", false, ErrMiss
+	}
+
+	d.hits.Add(1)
+	d.ns.hit(string(key))
+	stale := isStale(d.config, expiresAt, now)
+	common.LogInfo("快取命中", zap.String("鍵", string(key)), zap.Bool("stale", stale))
+	return value, stale, nil
+}
+
+// Set 設置緩存值，超過容量上限時淘汰最早到期的條目
+func (d *DiskCache) Set(ctx context.Context, prompt, imageData, value string) error {
+	key := []byte(generateKey(namespaceFrom(ctx), prompt, imageData))
+	size := int64(len(key) + len(value))
+	if size > d.config.Disk.MaxBytes {
+		d.errors.Add(1)
+		return common.ErrCacheFull
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now()
+	expiresAt := now.Add(lifetime(d.config)).UnixNano()
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expiresAt))
+	copy(data[8:], value)
+
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		entries, expiry := tx.Bucket(entriesBucket), tx.Bucket(expiryBucket)
+		if err := tx.Bucket(metaBucket).Put(key, newEntryMeta(prompt, imageData, now).encode()); err != nil {
+			return err
+		}
+
+		delta, count := size, int64(1)
+		if old := entries.Get(key); old != nil {
+			delta -= int64(len(key) + len(old) - 8)
+			count = 0
+			if len(old) >= 8 {
+				if err := expiry.Delete(expiryKey(decodeTime(old), key)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := entries.Put(key, data); err != nil {
+			return err
+		}
+		if err := expiry.Put(expiryKey(expiresAt, key), nil); err != nil {
+			return err
+		}
+		d.bytes.Add(delta)
+		d.entries.Add(count)
+
+		_, err := d.evict(tx, d.config.Disk.MaxBytes)
+		return err
+	})
+	if err != nil {
+		// 交易失敗時以檔案內容為準重新計算大小
+		d.errors.Add(1)
+		common.LogWarn("寫入磁碟快取失敗", zap.String("鍵", string(key)), zap.Error(err))
+		if loadErr := d.load(); loadErr != nil {
+			common.LogError("重新計算磁碟快取大小失敗", zap.Error(loadErr))
+		}
+		return fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	common.LogInfo("快取已儲存", zap.String("鍵", string(key)))
+	return nil
+}
+
+// Ping 檢查緩存檔案是否可讀取
+func (d *DiskCache) Ping(ctx context.Context) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.openErr != nil {
+		return fmt.Errorf("disk cache unavailable: %w", d.openErr)
+	}
+	return d.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(entriesBucket) == nil {
+			return fmt.Errorf("disk cache bucket missing")
+		}
+		return nil
+	})
+}
+
+// GetStats 獲取緩存統計信息
+func (d *DiskCache) GetStats() map[string]interface{} {
+	hits, misses := d.hits.Load(), d.misses.Load()
+	stats := map[string]interface{}{
+		"backend":    BackendDisk,
+		"path":       d.path,
+		"size":       d.entries.Load(),
+		"bytes":      d.bytes.Load(),
+		"max_bytes":  d.config.Disk.MaxBytes,
+		"hits":       hits,
+		"misses":     misses,
+		"evictions":  d.evictions.Load(),
+		"errors":     d.errors.Load(),
+		"namespaces": d.ns.snapshot(),
+	}
+	if info, err := os.Stat(d.path); err == nil {
+		stats["file_bytes"] = info.Size()
+	}
+	if hits+misses > 0 {
+		stats["hit_ratio"] = float64(hits) / float64(hits+misses)
+	}
+	return stats
+}
+
+// Close 停止定期壓縮並關閉檔案，內容保留供下次啟動載入
+func (d *DiskCache) Close() error {
+	close(d.stop)
+	<-d.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	common.LogInfo("磁碟快取已關閉",
+		zap.Int64("entries", d.entries.Load()),
+		zap.Int64("bytes", d.bytes.Load()),
+	)
+	return d.db.Close()
+}
+
+// load 掃描檔案計算條目數量與大小
+func (d *DiskCache) load() error {
+	var count, size int64
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+			count++
+			size += int64(len(k) + len(v) - 8)
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load disk cache: %w", err)
+	}
+	d.entries.Store(count)
+	d.bytes.Store(size)
+	return nil
+}
+
+// sweep 刪除已過期的條目，並在超過容量上限時淘汰最早到期的條目
+func (d *DiskCache) sweep() (expired, evicted int, err error) {
+	now := time.Now().UnixNano()
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		entries, expiry := tx.Bucket(entriesBucket), tx.Bucket(expiryBucket)
+
+		// 先收集再刪除，避免在走訪中修改 bucket
+		var stale [][]byte
+		c := expiry.Cursor()
+		for k, _ := c.First(); k != nil && int64(binary.BigEndian.Uint64(k[:8])) <= now; k, _ = c.Next() {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		for _, k := range stale {
+			key := k[8:]
+			if err := d.remove(entries, expiry, k, key); err != nil {
+				return err
+			}
+			expired++
+		}
+
+		var err error
+		evicted, err = d.evict(tx, d.config.Disk.MaxBytes)
+		return err
+	})
+	return expired, evicted, err
+}
+
+// evict 依到期時間由早到晚淘汰條目，直到總大小不超過 maxBytes
+func (d *DiskCache) evict(tx *bolt.Tx, maxBytes int64) (int, error) {
+	entries, expiry := tx.Bucket(entriesBucket), tx.Bucket(expiryBucket)
+
+	evicted := 0
+	for d.bytes.Load() > maxBytes {
+		k, _ := expiry.Cursor().First()
+		if k == nil {
+			break
+		}
+		k = append([]byte(nil), k...)
+		if err := d.remove(entries, expiry, k, k[8:]); err != nil {
+			return evicted, err
+		}
+		evicted++
+		d.evictions.Add(1)
+		d.ns.evict(string(k[8:]))
+	}
+	return evicted, nil
+}
+
+// remove 刪除條目、條目資訊與到期索引並更新大小統計
+func (d *DiskCache) remove(entries, expiry *bolt.Bucket, indexKey, key []byte) error {
+	if data := entries.Get(key); data != nil {
+		d.bytes.Add(-int64(len(key) + len(data) - 8))
+		d.entries.Add(-1)
+		if err := entries.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := entries.Tx().Bucket(metaBucket).Delete(key); err != nil {
+		return err
+	}
+	return expiry.Delete(indexKey)
+}
+
+// List 依鍵的順序列出以 prefix 開頭且未過期的條目
+func (d *DiskCache) List(ctx context.Context, prefix string, limit int) ([]Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	result := []Entry{}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		c := tx.Bucket(entriesBucket).Cursor()
+		for k, v := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = c.Next() {
+			if len(v) < 8 || now >= decodeTime(v) {
+				continue
+			}
+			expiresAt := time.Unix(0, decodeTime(v))
+			result = append(result, decodeEntryMeta(meta.Get(k)).entry(string(k), len(v)-8, staleAt(d.config, expiresAt), expiresAt))
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache: %w", err)
+	}
+	return result, nil
+}
+
+// Inspect 獲取單一條目與其值，不計入命中統計
+func (d *DiskCache) Inspect(ctx context.Context, key string) (*Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var entry *Entry
+	err := d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(entriesBucket).Get([]byte(key))
+		if len(data) < 8 || time.Now().UnixNano() >= decodeTime(data) {
+			return nil
+		}
+		expiresAt := time.Unix(0, decodeTime(data))
+		e := decodeEntryMeta(tx.Bucket(metaBucket).Get([]byte(key))).entry(key, len(data)-8, staleAt(d.config, expiresAt), expiresAt)
+		e.Value = string(data[8:])
+		entry = &e
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache: %w", err)
+	}
+	if entry == nil {
+		return nil, ErrMiss
+	}
+	return entry, nil
+}
+
+// Delete 刪除條目
+func (d *DiskCache) Delete(ctx context.Context, key string) (bool, error) {
+	n, err := d.deleteMatching([]byte(key), func(k []byte) bool { return string(k) == key })
+	return n > 0, err
+}
+
+// DeletePrefix 刪除鍵以 prefix 開頭的所有條目
+func (d *DiskCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	return d.deleteMatching([]byte(prefix), func(k []byte) bool { return strings.HasPrefix(string(k), prefix) })
+}
+
+// Purge 清空所有條目，空出的空間由定期壓縮回收
+func (d *DiskCache) Purge(ctx context.Context) (int, error) {
+	return d.DeletePrefix(ctx, "")
+}
+
+// deleteMatching 從 seek 開始依鍵的順序刪除符合 match 的條目，遇到第一個不符合的鍵即停止
+func (d *DiskCache) deleteMatching(seek []byte, match func(key []byte) bool) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	deleted := 0
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		entries, expiry := tx.Bucket(entriesBucket), tx.Bucket(expiryBucket)
+
+		// 先收集再刪除，避免在走訪中修改 bucket
+		var keys, indexKeys [][]byte
+		c := entries.Cursor()
+		for k, v := c.Seek(seek); k != nil && match(k); k, v = c.Next() {
+			var expiresAt int64
+			if len(v) >= 8 {
+				expiresAt = decodeTime(v)
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			indexKeys = append(indexKeys, expiryKey(expiresAt, k))
+		}
+		for i, key := range keys {
+			if err := d.remove(entries, expiry, indexKeys[i], key); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		d.errors.Add(1)
+		return 0, fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+	return deleted, nil
+}
+
+// startCompaction 每個清理週期刪除過期條目，並在空閒頁面過多時重寫檔案
+func (d *DiskCache) startCompaction() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.compact()
+		}
+	}
+}
+
+// compact 清理過期條目；bbolt 刪除資料後不會縮小檔案，空閒空間超過檔案一半時重寫為新檔
+func (d *DiskCache) compact() {
+	if !d.retryOpen() {
+		return
+	}
+
+	d.mu.RLock()
+	expired, evicted, err := d.sweep()
+	freeBytes := d.db.Stats().FreeAlloc
+	d.mu.RUnlock()
+	if err != nil {
+		d.errors.Add(1)
+		common.LogError("磁碟快取清理失敗", zap.Error(err))
+		return
+	}
+	if expired > 0 || evicted > 0 {
+		common.LogInfo("磁碟快取清理完成",
+			zap.Int("expired", expired),
+			zap.Int("evicted", evicted),
+			zap.Int64("entries", d.entries.Load()),
+			zap.Int64("bytes", d.bytes.Load()),
+		)
+	}
+
+	info, err := os.Stat(d.path)
+	if err != nil || int64(freeBytes) <= info.Size()/2 {
+		return
+	}
+	if err := d.rewrite(); err != nil {
+		d.errors.Add(1)
+		common.LogError("磁碟快取壓縮失敗", zap.Error(err))
+		return
+	}
+	if after, err := os.Stat(d.path); err == nil {
+		common.LogInfo("磁碟快取已壓縮",
+			zap.Int64("before_bytes", info.Size()),
+			zap.Int64("after_bytes", after.Size()),
+		)
+	}
+}
+
+// rewrite 將所有條目複製至新檔案後取代原檔，期間暫停讀寫
+func (d *DiskCache) rewrite() error {
+	tmpPath := d.path + ".compact"
+	os.Remove(tmpPath)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dst, err := bolt.Open(tmpPath, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	if err := bolt.Compact(dst, d.db, 0); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	// bbolt 關閉失敗時檔案同樣已關閉，不取代原檔，直接重新開啟
+	closeErr := d.db.Close()
+	var renameErr error
+	if closeErr == nil {
+		renameErr = os.Rename(tmpPath, d.path)
+	}
+	if closeErr != nil || renameErr != nil {
+		os.Remove(tmpPath)
+	}
+
+	// 無論是否成功取代都需重新開啟，否則之後的讀寫會失敗
+	if err := d.reopen(); err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close disk cache before replacing: %w", closeErr)
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to replace disk cache with compacted file: %w", renameErr)
+	}
+	return nil
+}
+
+// reopen 重新開啟緩存檔案，呼叫端需持有 d.mu 寫鎖；失敗時標記後端不可用，
+// 由 Ping 回報至 /ready，並於下一個清理週期重試
+func (d *DiskCache) reopen() error {
+	db, err := openDisk(d.path)
+	if err != nil {
+		d.openErr = err
+		return err
+	}
+	d.db = db
+	d.openErr = nil
+	return nil
+}
+
+// retryOpen 後端因重新開啟失敗而不可用時再次嘗試開啟，返回後端是否可用
+func (d *DiskCache) retryOpen() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.openErr == nil {
+		return true
+	}
+	if err := d.reopen(); err != nil {
+		d.errors.Add(1)
+		common.LogError("磁碟快取仍無法開啟", zap.String("path", d.path), zap.Error(err))
+		return false
+	}
+	if err := d.load(); err != nil {
+		common.LogError("重新計算磁碟快取大小失敗", zap.Error(err))
+	}
+	common.LogInfo("磁碟快取已重新開啟", zap.String("path", d.path))
+	return true
+}
+
+// expiryKey 到期索引鍵：8 bytes 大端序到期時間 + 緩存鍵
+func expiryKey(expiresAt int64, key []byte) []byte {
+	k := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(k, uint64(expiresAt))
+	copy(k[8:], key)
+	return k
+}
+
+// decodeTime 讀取條目開頭的到期時間
+func decodeTime(data []byte) int64 {
+	return int64(binary.BigEndian.Uint64(data[:8]))
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry 緩存條目摘要，供管理 API 列出與檢視；圖片數據只保留長度與哈希
+type Entry struct {
+	Key       string    `json:"key"` // 不含 Redis 前綴的緩存鍵，格式為 <命名空間>:text|multimodal:<哈希>
+	Namespace string    `json:"namespace"`
+	Prompt    string    `json:"prompt,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Size      int       `json:"size"` // 值的大小（bytes）
+	CreatedAt time.Time `json:"created_at"`
+	StaleAt   time.Time `json:"stale_at"`        // 超過 CACHE_TTL 的時間，之後讀取會回傳舊值並於背景更新
+	ExpiresAt time.Time `json:"expires_at"`      // 超過 CACHE_STALE_TTL、條目被移除的時間
+	Value     string    `json:"value,omitempty"` // 僅檢視單一條目時提供
+}
+
+// entryMeta 與值分開儲存的條目資訊，寫入時即遮蔽圖片數據
+type entryMeta struct {
+	Prompt    string    `json:"prompt"`
+	Image     string    `json:"image,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newEntryMeta 建立條目資訊
+func newEntryMeta(prompt, imageData string, now time.Time) entryMeta {
+	return entryMeta{
+		Prompt:    prompt,
+		Image:     redactImage(imageData),
+		CreatedAt: now,
+	}
+}
+
+// encode 序列化條目資訊
+func (m entryMeta) encode() []byte {
+	data, _ := json.Marshal(m)
+	return data
+}
+
+// decodeEntryMeta 解析條目資訊，格式錯誤時返回空值
+func decodeEntryMeta(data []byte) entryMeta {
+	var m entryMeta
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+// entry 組成條目摘要
+func (m entryMeta) entry(key string, size int, staleAt, expiresAt time.Time) Entry {
+	return Entry{
+		Key:       key,
+		Namespace: namespaceOf(key),
+		Prompt:    m.Prompt,
+		Image:     m.Image,
+		Size:      size,
+		CreatedAt: m.CreatedAt,
+		StaleAt:   staleAt,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// redactImage 遮蔽圖片數據，只保留長度與哈希；感知哈希鍵本身不含圖片內容，原樣保留
+func redactImage(imageData string) string {
+	if imageData == "" || strings.HasPrefix(imageData, "dhash:") {
+		return imageData
+	}
+	return fmt.Sprintf("[redacted %d bytes sha256:%s]", len(imageData), hashString(imageData)[:16])
+}
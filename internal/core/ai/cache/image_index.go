@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/image"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// 圖片緩存鍵模式
+const (
+	ImageKeySHA256 = "sha256" // 以圖片內容的 SHA-256 為鍵，只有完全相同的圖片會命中
+	ImageKeyDHash  = "dhash"  // 以感知哈希為鍵，視覺上相近的圖片共用同一筆緩存
+)
+
+// ImageIndex 感知哈希索引：以 BK-tree 依漢明距離尋找最接近的已知圖片，
+// 距離不超過門檻時沿用其哈希作為緩存鍵，讓連續拍攝的相近畫面共用辨識結果
+type ImageIndex struct {
+	mu        sync.Mutex
+	threshold int
+	maxSize   int
+	root      *bkNode
+	size      int
+}
+
+// bkNode BK-tree 節點，子節點依與本節點的漢明距離索引
+type bkNode struct {
+	hash     uint64
+	lastSeen time.Time
+	children map[int]*bkNode
+}
+
+// NewImageIndex 創建感知哈希索引：漢明距離不超過 threshold 視為同一張圖片，
+// 超過 maxSize 個哈希時只保留最近使用的一半
+func NewImageIndex(threshold, maxSize int) *ImageIndex {
+	return &ImageIndex{
+		threshold: threshold,
+		maxSize:   maxSize,
+	}
+}
+
+// Key 獲取圖片的緩存鍵：找到距離門檻內最接近的已知哈希時沿用之，否則加入索引
+func (x *ImageIndex) Key(imageData string) (string, error) {
+	hash, err := image.DHash(imageData)
+	if err != nil {
+		return "", err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	now := time.Now()
+	if node, distance := x.nearest(hash); node != nil {
+		node.lastSeen = now
+		if distance > 0 {
+			common.LogDebug("相近圖片共用快取鍵",
+				zap.String("hash", fmt.Sprintf("%016x", hash)),
+				zap.String("matched", fmt.Sprintf("%016x", node.hash)),
+				zap.Int("distance", distance),
+			)
+		}
+		return imageKey(node.hash), nil
+	}
+
+	x.insert(&bkNode{hash: hash, lastSeen: now})
+	if x.size > x.maxSize {
+		x.prune()
+	}
+	return imageKey(hash), nil
+}
+
+// nearest 在距離門檻內尋找最接近的節點；依三角不等式只走訪距離區間內的子節點
+func (x *ImageIndex) nearest(hash uint64) (*bkNode, int) {
+	var best *bkNode
+	bestDistance := x.threshold + 1
+	if x.root == nil {
+		return nil, 0
+	}
+
+	stack := []*bkNode{x.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := image.HammingDistance(hash, node.hash)
+		if d < bestDistance {
+			best, bestDistance = node, d
+			if d == 0 {
+				break
+			}
+		}
+		radius := x.threshold
+		if bestDistance-1 < radius {
+			radius = bestDistance - 1
+		}
+		for k, child := range node.children {
+			if k >= d-radius && k <= d+radius {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return best, bestDistance
+}
+
+// insert 將節點加入 BK-tree
+func (x *ImageIndex) insert(n *bkNode) {
+	x.size++
+	if x.root == nil {
+		x.root = n
+		return
+	}
+
+	node := x.root
+	for {
+		d := image.HammingDistance(n.hash, node.hash)
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+			node.children[d] = n
+			return
+		}
+		node = child
+	}
+}
+
+// prune 保留最近使用的一半哈希並重建 BK-tree
+func (x *ImageIndex) prune() {
+	nodes := make([]*bkNode, 0, x.size)
+	stack := []*bkNode{x.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].lastSeen.After(nodes[j].lastSeen)
+	})
+
+	before := x.size
+	x.root, x.size = nil, 0
+	for _, node := range nodes[:x.maxSize/2] {
+		x.insert(&bkNode{hash: node.hash, lastSeen: node.lastSeen})
+	}
+	common.LogInfo("感知哈希索引已精簡",
+		zap.Int("before", before),
+		zap.Int("after", x.size),
+	)
+}
+
+// imageKey 以感知哈希表示的圖片緩存鍵
+func imageKey(hash uint64) string {
+	return fmt.Sprintf("dhash:%016x", hash)
+}
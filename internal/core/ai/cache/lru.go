@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lru 以完整緩存鍵索引的固定容量 LRU，供分層緩存的 L1 使用
+type lru struct {
+	mu      sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	items   map[string]*list.Element
+	order   *list.List // 最近使用的在前
+}
+
+// lruEntry LRU 條目
+type lruEntry struct {
+	key       string
+	value     string
+	staleAt   time.Time // 來源條目超過 CACHE_TTL 的時間，零值表示不會變舊
+	expiresAt time.Time
+}
+
+// newLRU 創建 LRU，超過 maxSize 時淘汰最久未使用的條目
+func newLRU(maxSize int, ttl time.Duration) *lru {
+	return &lru{
+		maxSize: maxSize,
+		ttl:     ttl,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get 獲取未過期的值與是否已超過來源條目的 CACHE_TTL
+func (l *lru) get(key string) (string, bool, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return "", false, false
+	}
+	entry := elem.Value.(*lruEntry)
+	now := time.Now()
+	if now.After(entry.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.items, key)
+		return "", false, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, !entry.staleAt.IsZero() && !now.Before(entry.staleAt), true
+}
+
+// set 設置值，存活時間為 L1 的 ttl，且不超過來源條目的到期時間（零值表示不限制）
+func (l *lru) set(key, value string, staleAt, sourceExpiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(l.ttl)
+	if !sourceExpiresAt.IsZero() && sourceExpiresAt.Before(expiresAt) {
+		expiresAt = sourceExpiresAt
+	}
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.staleAt = staleAt
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, staleAt: staleAt, expiresAt: expiresAt})
+	for l.order.Len() > l.maxSize {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove 移除條目，返回是否存在
+func (l *lru) remove(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return false
+	}
+	l.order.Remove(elem)
+	delete(l.items, key)
+	return true
+}
+
+// removePrefix 移除鍵以 prefix 開頭的所有條目，返回移除數量
+func (l *lru) removePrefix(prefix string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := 0
+	for key, elem := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.order.Remove(elem)
+			delete(l.items, key)
+			count++
+		}
+	}
+	return count
+}
+
+// len 獲取條目數量
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// clear 清空所有條目
+func (l *lru) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.items = make(map[string]*list.Element)
+	l.order.Init()
+}
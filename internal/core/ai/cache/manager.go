@@ -5,6 +5,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +22,7 @@ type CacheManager struct {
 	mu     sync.RWMutex
 	store  map[string]cacheEntry
 	stats  cacheStats
+	ns     namespaceStats
 }
 
 // cacheEntry 緩存條目
@@ -27,6 +30,7 @@ type cacheEntry struct {
 	value       string
 	expiresAt   time.Time
 	imageHash   string
+	meta        entryMeta
 	createdAt   time.Time
 	lastAccess  time.Time
 	accessCount int
@@ -59,67 +63,74 @@ func NewManager(cfg *config.Config) *CacheManager {
 	common.LogInfo("快取管理員已初始化",
 		zap.Int("最大容量", cfg.Cache.MaxSize),
 		zap.Duration("存活時間", cfg.Cache.TTL),
+		zap.Duration("舊值保留時間", cfg.Cache.StaleTTL),
 		zap.Duration("清理間隔", cfg.Cache.CleanupInterval),
 	)
 
 	return m
 }
 
-// Get 獲取緩存值
-func (m *CacheManager) Get(ctx context.Context, prompt, imageData string) (string, error) {
+// Get 獲取緩存值，超過 CACHE_TTL 的值標記為 stale
+func (m *CacheManager) Get(ctx context.Context, prompt, imageData string) (string, bool, error) {
 	if !m.config.Cache.Enabled {
 		common.LogInfo("Cache disabled, skipping lookup")
-		return "", common.ErrCacheDisabled
+		return "", false, common.ErrCacheDisabled
 	}
 
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	// 命中時需更新訪問統計，因此使用寫鎖
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	// 生成緩存鍵
-	key := m.generateKey(prompt, imageData)
+	key := generateKey(namespaceFrom(ctx), prompt, imageData)
 
 	// 檢查緩存
 	if entry, exists := m.store[key]; exists {
 		// 檢查是否過期
-		if time.Now().After(entry.expiresAt) {
-			m.mu.RUnlock()
-			m.mu.Lock()
+		now := time.Now()
+		if now.After(entry.expiresAt) {
 			delete(m.store, key)
 			m.stats.evictions++
-			m.mu.Unlock()
-			m.mu.RLock()
+			m.ns.evict(key)
+			m.stats.misses++
+			m.ns.miss(key)
 			common.LogInfo("快取已過期",
 				zap.String("鍵", key),
 			)
-			return "", common.ErrCacheDisabled
+			return "", false, common.ErrCacheDisabled
 		}
 
 		// 檢查圖片哈希是否匹配
 		if imageData != "" && entry.imageHash != m.hashImage(imageData) {
 			m.stats.misses++
+			m.ns.miss(key)
 			common.LogInfo("快取因圖片變更未命中",
 				zap.String("鍵", key),
 			)
-			return "", fmt.Errorf("image changed")
+			return "", false, fmt.Errorf("image changed")
 		}
 
 		// 更新訪問統計
-		entry.lastAccess = time.Now()
+		entry.lastAccess = now
 		entry.accessCount++
 		m.store[key] = entry
 		m.stats.hits++
+		m.ns.hit(key)
 
+		stale := isStale(m.config.Cache, entry.expiresAt, now)
 		common.LogInfo("快取命中",
 			zap.String("鍵", key),
+			zap.Bool("stale", stale),
 		)
-		return entry.value, nil
+		return entry.value, stale, nil
 	}
 
 	m.stats.misses++
+	m.ns.miss(key)
 	common.LogInfo("快取未命中",
 		zap.String("鍵", key),
 	)
-	return "", common.ErrCacheDisabled
+	return "", false, common.ErrCacheDisabled
 }
 
 // Set 設置緩存值
@@ -156,14 +167,15 @@ func (m *CacheManager) Set(ctx context.Context, prompt, imageData, value string)
 	}
 
 	// 生成緩存鍵
-	key := m.generateKey(prompt, imageData)
+	key := generateKey(namespaceFrom(ctx), prompt, imageData)
 
 	// 設置緩存
 	now := time.Now()
 	m.store[key] = cacheEntry{
 		value:       value,
-		expiresAt:   now.Add(m.config.Cache.TTL),
+		expiresAt:   now.Add(lifetime(m.config.Cache)),
 		imageHash:   m.hashImage(imageData),
+		meta:        newEntryMeta(prompt, imageData, now),
 		createdAt:   now,
 		lastAccess:  now,
 		accessCount: 0,
@@ -176,23 +188,23 @@ func (m *CacheManager) Set(ctx context.Context, prompt, imageData, value string)
 	return nil
 }
 
-// generateKey 生成緩存鍵
-func (m *CacheManager) generateKey(prompt, imageData string) string {
+// generateKey 生成緩存鍵：以命名空間開頭，純文字與含圖片的請求分開，內容以 SHA-256 哈希表示
+func generateKey(namespace, prompt, imageData string) string {
 	if imageData == "" {
-		return fmt.Sprintf("text:%s", m.hashString(prompt))
+		return fmt.Sprintf("%s:text:%s", namespace, hashString(prompt))
 	}
-	return fmt.Sprintf("multimodal:%s:%s", m.hashString(prompt), m.hashImage(imageData))
+	return fmt.Sprintf("%s:multimodal:%s:%s", namespace, hashString(prompt), hashString(imageData))
 }
 
 // hashString 計算字符串的 SHA-256 哈希值
-func (m *CacheManager) hashString(s string) string {
+func hashString(s string) string {
 	hash := sha256.Sum256([]byte(s))
 	return hex.EncodeToString(hash[:])
 }
 
 // hashImage 計算圖片數據的哈希值
 func (m *CacheManager) hashImage(imageData string) string {
-	return m.hashString(imageData)
+	return hashString(imageData)
 }
 
 // startCleanup 啟動清理過期緩存的協程
@@ -215,6 +227,7 @@ func (m *CacheManager) cleanup() int {
 			delete(m.store, key)
 			count++
 			m.stats.evictions++
+			m.ns.evict(key)
 		}
 	}
 
@@ -223,7 +236,6 @@ func (m *CacheManager) cleanup() int {
 			zap.Int("count", count),
 			zap.Int64("total_evictions", m.stats.evictions),
 			zap.Int("remaining_size", len(m.store)),
-			zap.Float64("eviction_ratio", float64(m.stats.evictions)/float64(m.stats.hits+m.stats.misses)),
 		)
 	}
 
@@ -250,6 +262,7 @@ func (m *CacheManager) evictLRU() {
 	if oldestKey != "" {
 		delete(m.store, oldestKey)
 		m.stats.evictions++
+		m.ns.evict(oldestKey)
 		common.LogInfo("快取已淘汰(LRU)",
 			zap.String("鍵", oldestKey),
 		)
@@ -261,15 +274,100 @@ func (m *CacheManager) GetStats() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return map[string]interface{}{
-		"size":      len(m.store),
-		"max_size":  m.config.Cache.MaxSize,
-		"hits":      m.stats.hits,
-		"misses":    m.stats.misses,
-		"evictions": m.stats.evictions,
-		"errors":    m.stats.errors,
-		"hit_ratio": float64(m.stats.hits) / float64(m.stats.hits+m.stats.misses),
+	stats := map[string]interface{}{
+		"backend":    BackendMemory,
+		"size":       len(m.store),
+		"max_size":   m.config.Cache.MaxSize,
+		"hits":       m.stats.hits,
+		"misses":     m.stats.misses,
+		"evictions":  m.stats.evictions,
+		"errors":     m.stats.errors,
+		"namespaces": m.ns.snapshot(),
+	}
+	// 尚無查詢時不回報命中率，避免除以零
+	if total := m.stats.hits + m.stats.misses; total > 0 {
+		stats["hit_ratio"] = float64(m.stats.hits) / float64(total)
+	}
+	return stats
+}
+
+// List 列出鍵以 prefix 開頭且未過期的條目，依鍵排序
+func (m *CacheManager) List(ctx context.Context, prefix string, limit int) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(m.store))
+	for key, entry := range m.store {
+		if strings.HasPrefix(key, prefix) && now.Before(entry.expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		entry := m.store[key]
+		entries = append(entries, entry.meta.entry(key, len(entry.value), staleAt(m.config.Cache, entry.expiresAt), entry.expiresAt))
+	}
+	return entries, nil
+}
+
+// Inspect 獲取單一條目與其值，不計入命中統計
+func (m *CacheManager) Inspect(ctx context.Context, key string) (*Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.store[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, ErrMiss
 	}
+	e := entry.meta.entry(key, len(entry.value), staleAt(m.config.Cache, entry.expiresAt), entry.expiresAt)
+	e.Value = entry.value
+	return &e, nil
+}
+
+// Delete 刪除條目，返回是否存在
+func (m *CacheManager) Delete(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.store[key]
+	delete(m.store, key)
+	return ok, nil
+}
+
+// DeletePrefix 刪除鍵以 prefix 開頭的所有條目
+func (m *CacheManager) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for key := range m.store {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.store, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Purge 清空所有條目，統計保留
+func (m *CacheManager) Purge(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := len(m.store)
+	m.store = make(map[string]cacheEntry)
+	return count, nil
+}
+
+// Ping 檢查緩存後端是否可用，記憶體緩存沒有外部依賴，一律可用
+func (m *CacheManager) Ping(ctx context.Context) error {
+	return nil
 }
 
 // Close 關閉緩存管理器
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// DefaultNamespace 未指定命名空間時使用的命名空間
+const DefaultNamespace = "default"
+
+// namespaceKey context 中命名空間的鍵
+type namespaceKey struct{}
+
+// WithNamespace 指定之後緩存操作的命名空間（通常為 AI 任務類型），
+// 命名空間是緩存鍵的第一段，用於分別統計命中率與依前綴刪除
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// namespaceFrom 獲取 context 中的命名空間
+func namespaceFrom(ctx context.Context) string {
+	if ctx != nil {
+		if namespace, ok := ctx.Value(namespaceKey{}).(string); ok {
+			return namespace
+		}
+	}
+	return DefaultNamespace
+}
+
+// namespaceOf 獲取緩存鍵（不含 Redis 前綴）所屬的命名空間
+func namespaceOf(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	return namespace
+}
+
+// namespaceStats 依命名空間分別記錄命中、未命中與淘汰次數
+type namespaceStats struct {
+	mu       sync.Mutex
+	counters map[string]*namespaceCounters
+
+	// untrackedEvictions 後端無法得知淘汰（如 Redis 自行處理過期與記憶體淘汰），快照中的 evictions 為 null 而非 0
+	untrackedEvictions bool
+}
+
+// namespaceCounters 單一命名空間的統計
+type namespaceCounters struct {
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// counter 獲取命名空間的統計，呼叫端需持有鎖
+func (s *namespaceStats) counter(namespace string) *namespaceCounters {
+	if s.counters == nil {
+		s.counters = make(map[string]*namespaceCounters)
+	}
+	c, ok := s.counters[namespace]
+	if !ok {
+		c = &namespaceCounters{}
+		s.counters[namespace] = c
+	}
+	return c
+}
+
+// hit 記錄命中
+func (s *namespaceStats) hit(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter(namespaceOf(key)).hits++
+}
+
+// miss 記錄未命中
+func (s *namespaceStats) miss(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter(namespaceOf(key)).misses++
+}
+
+// evict 記錄淘汰
+func (s *namespaceStats) evict(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter(namespaceOf(key)).evictions++
+}
+
+// snapshot 獲取各命名空間的統計，尚無查詢的命名空間不回報命中率，無法得知淘汰時 evictions 為 null
+func (s *namespaceStats) snapshot() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]interface{}, len(s.counters))
+	for namespace, c := range s.counters {
+		stats := map[string]interface{}{
+			"hits":      c.hits,
+			"misses":    c.misses,
+			"evictions": c.evictions,
+		}
+		if s.untrackedEvictions {
+			stats["evictions"] = nil
+		}
+		if total := c.hits + c.misses; total > 0 {
+			stats["hit_ratio"] = float64(c.hits) / float64(total)
+		}
+		out[namespace] = stats
+	}
+	return out
+}
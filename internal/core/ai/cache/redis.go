@@ -0,0 +1,345 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// ErrMiss 緩存未命中
+var ErrMiss = errors.New("cache miss")
+
+// metaPrefix 條目資訊的鍵前綴（接在 CACHE_REDIS_PREFIX 之後），與值分開儲存以免讀取時多傳輸資料
+const metaPrefix = "meta:"
+
+// scanCount 每次 SCAN 建議返回的鍵數量
+const scanCount = 500
+
+// RedisCache Redis 緩存，多個副本共用同一份緩存
+type RedisCache struct {
+	client *redis.Client
+	config config.CacheConfig
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+	ns     namespaceStats
+}
+
+// NewRedis 創建 Redis 緩存並測試連接
+func NewRedis(cfg config.CacheConfig) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	// 測試連接
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", cfg.Redis.Addr, err)
+	}
+
+	common.LogInfo("Redis 快取已初始化",
+		zap.String("addr", cfg.Redis.Addr),
+		zap.Int("db", cfg.Redis.DB),
+		zap.String("prefix", cfg.Redis.Prefix),
+		zap.Duration("ttl", cfg.TTL),
+		zap.Duration("stale_ttl", cfg.StaleTTL),
+	)
+
+	return &RedisCache{
+		client: client,
+		config: cfg,
+		ns:     namespaceStats{untrackedEvictions: true},
+	}, nil
+}
+
+// Get 獲取緩存值，連線錯誤同樣視為未命中並返回錯誤；依剩餘存活時間判斷是否已超過 CACHE_TTL
+func (c *RedisCache) Get(ctx context.Context, prompt, imageData string) (string, bool, error) {
+	value, expiresAt, err := c.getKey(ctx, c.key(namespaceFrom(ctx), prompt, imageData))
+	if err != nil {
+		return "", false, err
+	}
+	return value, !expiresAt.IsZero() && isStale(c.config, expiresAt, time.Now()), nil
+}
+
+// Set 設置緩存值與條目資訊
+func (c *RedisCache) Set(ctx context.Context, prompt, imageData, value string) error {
+	return c.setKey(ctx, c.key(namespaceFrom(ctx), prompt, imageData), value, newEntryMeta(prompt, imageData, time.Now()))
+}
+
+// getKey 以完整緩存鍵讀取值與到期時間，鍵未設定存活時間時到期時間為零值
+func (c *RedisCache) getKey(ctx context.Context, key string) (string, time.Time, error) {
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			c.misses.Add(1)
+			c.ns.miss(c.trim(key))
+			common.LogInfo("快取未命中", zap.String("鍵", key))
+			return "", time.Time{}, ErrMiss
+		}
+		c.errors.Add(1)
+		common.LogWarn("讀取 Redis 快取失敗", zap.String("鍵", key), zap.Error(err))
+		return "", time.Time{}, fmt.Errorf("failed to get cache: %w", err)
+	}
+
+	var expiresAt time.Time
+	if d := ttl.Val(); d > 0 {
+		expiresAt = time.Now().Add(d)
+	}
+	c.hits.Add(1)
+	c.ns.hit(c.trim(key))
+	common.LogInfo("快取命中", zap.String("鍵", key))
+	return get.Val(), expiresAt, nil
+}
+
+// setKey 以完整緩存鍵寫入值與條目資訊
+func (c *RedisCache) setKey(ctx context.Context, key, value string, meta entryMeta) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, lifetime(c.config))
+		pipe.Set(ctx, c.metaKey(key), meta.encode(), lifetime(c.config))
+		return nil
+	})
+	if err != nil {
+		c.errors.Add(1)
+		common.LogWarn("寫入 Redis 快取失敗", zap.String("鍵", key), zap.Error(err))
+		return fmt.Errorf("failed to set cache: %w", err)
+	}
+
+	common.LogInfo("快取已儲存", zap.String("鍵", key))
+	return nil
+}
+
+// Ping 檢查 Redis 連線
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping failed: %w", err)
+	}
+	return nil
+}
+
+// GetStats 獲取緩存統計信息
+func (c *RedisCache) GetStats() map[string]interface{} {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats := map[string]interface{}{
+		"backend":    BackendRedis,
+		"hits":       hits,
+		"misses":     misses,
+		"errors":     c.errors.Load(),
+		"evictions":  nil, // 過期與淘汰由 Redis 處理，無法計數
+		"namespaces": c.ns.snapshot(),
+	}
+	if hits+misses > 0 {
+		stats["hit_ratio"] = float64(hits) / float64(hits+misses)
+	}
+	return stats
+}
+
+// Close 關閉 Redis 連線
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// List 以 SCAN 列出鍵以 prefix 開頭的條目，順序不固定
+func (c *RedisCache) List(ctx context.Context, prefix string, limit int) ([]Entry, error) {
+	var keys []string
+	err := c.scan(ctx, prefix, func(batch []string) (bool, error) {
+		keys = append(keys, batch...)
+		return limit > 0 && len(keys) >= limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for start := 0; start < len(keys); start += scanCount {
+		batch := keys[start:min(start+scanCount, len(keys))]
+		found, err := c.describe(ctx, batch, false)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+	return entries, nil
+}
+
+// Inspect 獲取單一條目與其值，不計入命中統計
+func (c *RedisCache) Inspect(ctx context.Context, key string) (*Entry, error) {
+	entries, err := c.describe(ctx, []string{c.config.Redis.Prefix + key}, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrMiss
+	}
+	return &entries[0], nil
+}
+
+// Delete 刪除條目與其資訊
+func (c *RedisCache) Delete(ctx context.Context, key string) (bool, error) {
+	n, err := c.del(ctx, []string{c.config.Redis.Prefix + key})
+	return n > 0, err
+}
+
+// DeletePrefix 以 SCAN 找出鍵以 prefix 開頭的條目並分批刪除
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	deleted := 0
+	err := c.scan(ctx, prefix, func(batch []string) (bool, error) {
+		n, err := c.del(ctx, batch)
+		deleted += n
+		return false, err
+	})
+	return deleted, err
+}
+
+// Purge 刪除 CACHE_REDIS_PREFIX 下的所有條目，同一 Redis 中的其他資料不受影響
+func (c *RedisCache) Purge(ctx context.Context) (int, error) {
+	return c.DeletePrefix(ctx, "")
+}
+
+// scan 走訪鍵以 prefix 開頭的條目（完整 Redis 鍵，不含條目資訊），fn 返回 true 時停止
+func (c *RedisCache) scan(ctx context.Context, prefix string, fn func(keys []string) (bool, error)) error {
+	pattern := escapePattern(c.config.Redis.Prefix+prefix) + "*"
+	meta := c.config.Redis.Prefix + metaPrefix
+
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan cache: %w", err)
+		}
+
+		batch := keys[:0]
+		for _, key := range keys {
+			if !strings.HasPrefix(key, meta) {
+				batch = append(batch, key)
+			}
+		}
+		if len(batch) > 0 {
+			stop, err := fn(batch)
+			if err != nil || stop {
+				return err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// describe 讀取條目的大小、剩餘存活時間與資訊，已不存在的鍵會被略過
+func (c *RedisCache) describe(ctx context.Context, keys []string, withValue bool) ([]Entry, error) {
+	type pending struct {
+		key   string
+		value *redis.StringCmd
+		size  *redis.IntCmd
+		ttl   *redis.DurationCmd
+		meta  *redis.StringCmd
+	}
+
+	cmds := make([]pending, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pending{
+				key:  key,
+				size: pipe.StrLen(ctx, key),
+				ttl:  pipe.PTTL(ctx, key),
+				meta: pipe.Get(ctx, c.metaKey(key)),
+			}
+			if withValue {
+				cmds[i].value = pipe.Get(ctx, key)
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to read cache entries: %w", err)
+	}
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(keys))
+	for _, cmd := range cmds {
+		// PTTL 對不存在的鍵返回負值
+		ttl := cmd.ttl.Val()
+		if ttl < 0 && ttl != -1 {
+			continue
+		}
+		meta, _ := cmd.meta.Bytes()
+		expiresAt := now.Add(ttl)
+		entry := decodeEntryMeta(meta).entry(c.trim(cmd.key), int(cmd.size.Val()), staleAt(c.config, expiresAt), expiresAt)
+		if withValue {
+			entry.Value = cmd.value.Val()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// del 刪除條目（完整 Redis 鍵）與其資訊，返回刪除的條目數量
+func (c *RedisCache) del(ctx context.Context, keys []string) (int, error) {
+	var counts []*redis.IntCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			counts = append(counts, pipe.Del(ctx, key))
+			pipe.Del(ctx, c.metaKey(key))
+		}
+		return nil
+	})
+	if err != nil {
+		c.errors.Add(1)
+		return 0, fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+
+	deleted := 0
+	for _, n := range counts {
+		deleted += int(n.Val())
+	}
+	return deleted, nil
+}
+
+// key 生成帶前綴的緩存鍵
+func (c *RedisCache) key(namespace, prompt, imageData string) string {
+	return c.config.Redis.Prefix + generateKey(namespace, prompt, imageData)
+}
+
+// metaKey 獲取條目資訊的完整鍵
+func (c *RedisCache) metaKey(key string) string {
+	return c.config.Redis.Prefix + metaPrefix + c.trim(key)
+}
+
+// trim 去除 CACHE_REDIS_PREFIX，得到對外顯示的緩存鍵
+func (c *RedisCache) trim(key string) string {
+	return strings.TrimPrefix(key, c.config.Redis.Prefix)
+}
+
+// escapePattern 跳脫 Redis glob 的特殊字元
+func escapePattern(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
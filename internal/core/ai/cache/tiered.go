@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// invalidateChannel 失效通知頻道（接在 CACHE_REDIS_PREFIX 之後），訊息內容為 "<副本 ID> <緩存鍵>"，
+// 緩存鍵以 * 結尾時表示移除該前綴的所有條目
+const invalidateChannel = "invalidate"
+
+// TieredCache 分層緩存：程序內 LRU（L1）在前、Redis（L2）在後。
+// 讀取時 L1 未命中才查詢 L2 並回填 L1；寫入時同時寫入兩層，
+// 並透過 pub/sub 通知其他副本移除 L1 中的舊值
+type TieredCache struct {
+	l1       *lru
+	l2       *RedisCache
+	instance string
+	channel  string
+	pubsub   *redis.PubSub
+
+	l1Hits        atomic.Int64
+	l1Misses      atomic.Int64
+	invalidations atomic.Int64
+	ns            namespaceStats
+}
+
+// NewTiered 創建分層緩存並訂閱失效通知
+func NewTiered(cfg config.CacheConfig) (*TieredCache, error) {
+	l2, err := NewRedis(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TieredCache{
+		l1:       newLRU(cfg.L1MaxSize, cfg.L1TTL),
+		l2:       l2,
+		instance: uuid.New().String(),
+		channel:  cfg.Redis.Prefix + invalidateChannel,
+		ns:       namespaceStats{untrackedEvictions: true},
+	}
+
+	// 等待訂閱確認，避免啟動後的第一批通知遺失
+	t.pubsub = l2.client.Subscribe(context.Background(), t.channel)
+	if _, err := t.pubsub.Receive(context.Background()); err != nil {
+		t.pubsub.Close()
+		l2.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", t.channel, err)
+	}
+	go t.listen()
+
+	common.LogInfo("分層快取已初始化",
+		zap.Int("l1_max_size", cfg.L1MaxSize),
+		zap.Duration("l1_ttl", cfg.L1TTL),
+		zap.String("channel", t.channel),
+		zap.String("instance", t.instance),
+	)
+	return t, nil
+}
+
+// Get 依序查詢 L1、L2，L2 命中時連同到期時間回填 L1
+func (t *TieredCache) Get(ctx context.Context, prompt, imageData string) (string, bool, error) {
+	key := t.l2.key(namespaceFrom(ctx), prompt, imageData)
+	if value, stale, ok := t.l1.get(key); ok {
+		t.l1Hits.Add(1)
+		t.ns.hit(t.l2.trim(key))
+		return value, stale, nil
+	}
+	t.l1Misses.Add(1)
+
+	value, expiresAt, err := t.l2.getKey(ctx, key)
+	if err != nil {
+		t.ns.miss(t.l2.trim(key))
+		return "", false, err
+	}
+	t.ns.hit(t.l2.trim(key))
+	if expiresAt.IsZero() {
+		t.l1.set(key, value, time.Time{}, time.Time{})
+		return value, false, nil
+	}
+	t.l1.set(key, value, staleAt(t.l2.config, expiresAt), expiresAt)
+	return value, isStale(t.l2.config, expiresAt, time.Now()), nil
+}
+
+// Set 寫入 L1 與 L2，成功寫入 L2 後通知其他副本移除 L1 中的舊值
+func (t *TieredCache) Set(ctx context.Context, prompt, imageData, value string) error {
+	key := t.l2.key(namespaceFrom(ctx), prompt, imageData)
+	now := time.Now()
+	t.l1.set(key, value, now.Add(t.l2.config.TTL), now.Add(lifetime(t.l2.config)))
+	if err := t.l2.setKey(ctx, key, value, newEntryMeta(prompt, imageData, now)); err != nil {
+		return err
+	}
+
+	t.publish(ctx, key)
+	return nil
+}
+
+// Ping 檢查 L2 連線
+func (t *TieredCache) Ping(ctx context.Context) error {
+	return t.l2.Ping(ctx)
+}
+
+// GetStats 獲取整體與各層的命中統計，整體命中為 L1 或 L2 任一層命中
+func (t *TieredCache) GetStats() map[string]interface{} {
+	l1Hits, l1Misses := t.l1Hits.Load(), t.l1Misses.Load()
+	l2Hits := t.l2.hits.Load()
+
+	l1 := map[string]interface{}{
+		"size":     t.l1.len(),
+		"max_size": t.l1.maxSize,
+		"hits":     l1Hits,
+		"misses":   l1Misses,
+	}
+	stats := map[string]interface{}{
+		"backend":       BackendTiered,
+		"hits":          l1Hits + l2Hits,
+		"misses":        l1Misses - l2Hits,
+		"invalidations": t.invalidations.Load(),
+		"namespaces":    t.ns.snapshot(),
+		"l1":            l1,
+		"l2":            t.l2.GetStats(),
+	}
+	if total := l1Hits + l1Misses; total > 0 {
+		l1["hit_ratio"] = float64(l1Hits) / float64(total)
+		stats["hit_ratio"] = float64(l1Hits+l2Hits) / float64(total)
+	}
+	return stats
+}
+
+// List 列出 L2 中鍵以 prefix 開頭的條目
+func (t *TieredCache) List(ctx context.Context, prefix string, limit int) ([]Entry, error) {
+	return t.l2.List(ctx, prefix, limit)
+}
+
+// Inspect 獲取 L2 中的單一條目與其值
+func (t *TieredCache) Inspect(ctx context.Context, key string) (*Entry, error) {
+	return t.l2.Inspect(ctx, key)
+}
+
+// Delete 刪除兩層中的條目，並通知其他副本移除 L1 中的條目
+func (t *TieredCache) Delete(ctx context.Context, key string) (bool, error) {
+	full := t.l2.config.Redis.Prefix + key
+	t.l1.remove(full)
+	ok, err := t.l2.Delete(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	t.publish(ctx, full)
+	return ok, nil
+}
+
+// DeletePrefix 刪除兩層中鍵以 prefix 開頭的條目，並通知其他副本
+func (t *TieredCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	full := t.l2.config.Redis.Prefix + prefix
+	t.l1.removePrefix(full)
+	n, err := t.l2.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return n, err
+	}
+	t.publish(ctx, full+"*")
+	return n, nil
+}
+
+// Purge 清空兩層的所有條目，並通知其他副本
+func (t *TieredCache) Purge(ctx context.Context) (int, error) {
+	return t.DeletePrefix(ctx, "")
+}
+
+// publish 通知其他副本移除 L1 中的條目，失敗時僅記錄，其他副本最多保留舊值 CACHE_L1_TTL
+func (t *TieredCache) publish(ctx context.Context, key string) {
+	if err := t.l2.client.Publish(ctx, t.channel, t.instance+" "+key).Err(); err != nil {
+		common.LogWarn("發送快取失效通知失敗", zap.String("鍵", key), zap.Error(err))
+	}
+}
+
+// Close 取消訂閱並關閉 L2 連線
+func (t *TieredCache) Close() error {
+	t.pubsub.Close()
+	t.l1.clear()
+	return t.l2.Close()
+}
+
+// listen 處理其他副本送出的失效通知，忽略自己送出的通知
+func (t *TieredCache) listen() {
+	for msg := range t.pubsub.Channel() {
+		instance, key, ok := strings.Cut(msg.Payload, " ")
+		if !ok || instance == t.instance {
+			continue
+		}
+		t.invalidations.Add(1)
+		if prefix, ok := strings.CutSuffix(key, "*"); ok {
+			n := t.l1.removePrefix(prefix)
+			common.LogDebug("已依通知移除 L1 快取", zap.String("前綴", prefix), zap.Int("數量", n))
+			continue
+		}
+		if t.l1.remove(key) {
+			common.LogDebug("已依通知移除 L1 快取", zap.String("鍵", key))
+		}
+	}
+}
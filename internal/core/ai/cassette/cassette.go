@@ -0,0 +1,343 @@
+package cassette
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// 運作模式
+const (
+	ModeRecord = "record" // 呼叫上游並將請求/回應寫入卡帶
+	ModeReplay = "replay" // 僅從卡帶回放，不連網
+)
+
+// ErrReplayMiss 回放模式下找不到對應的錄製紀錄
+var ErrReplayMiss = errors.New("cassette replay miss")
+
+// Interaction 一筆錄製的請求/回應
+type Interaction struct {
+	Fingerprint string            `json:"fingerprint"`
+	RecordedAt  time.Time         `json:"recorded_at"`
+	Request     RecordedRequest   `json:"request"`
+	Response    provider.Response `json:"response"`
+}
+
+// RecordedRequest 錄製的請求（圖片、回應結構與工具定義僅保存哈希）
+type RecordedRequest struct {
+	Model       string            `json:"model"`
+	Messages    []RecordedMessage `json:"messages"`
+	MaxTokens   int               `json:"max_tokens,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	TopP        *float64          `json:"top_p,omitempty"`
+	Stop        []string          `json:"stop,omitempty"`
+	Schema      string            `json:"schema,omitempty"` // 期望的回應結構（名稱@哈希）
+	Tools       []string          `json:"tools,omitempty"`  // 可呼叫的工具（名稱@定義哈希）
+	ToolChoice  string            `json:"tool_choice,omitempty"`
+}
+
+// RecordedMessage 錄製的消息
+type RecordedMessage struct {
+	Role       string              `json:"role"`
+	Content    string              `json:"content"`
+	ImageHash  string              `json:"image_hash,omitempty"`
+	ToolCalls  []provider.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string              `json:"tool_call_id,omitempty"`
+}
+
+// Provider 錄製/回放提供者，實作 provider.Provider
+type Provider struct {
+	mode         string
+	dir          string
+	model        string
+	timeout      time.Duration
+	upstream     provider.Provider
+	mu           sync.RWMutex
+	interactions map[string]*Interaction
+}
+
+var _ provider.Provider = (*Provider)(nil)
+
+// NewRecorder 創建錄製提供者，所有請求轉發至 upstream 並寫入 dir
+func NewRecorder(upstream provider.Provider, dir string) (*Provider, error) {
+	if upstream == nil {
+		return nil, fmt.Errorf("upstream provider is required for record mode")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	p := &Provider{
+		mode:         ModeRecord,
+		dir:          dir,
+		model:        upstream.GetModel(),
+		timeout:      upstream.GetTimeout(),
+		upstream:     upstream,
+		interactions: make(map[string]*Interaction),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewReplayer 創建回放提供者，model 為請求未指定模型時使用的預設模型
+func NewReplayer(dir string, model string) (*Provider, error) {
+	p := &Provider{
+		mode:         ModeReplay,
+		dir:          dir,
+		model:        model,
+		interactions: make(map[string]*Interaction),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	common.LogInfo("卡帶回放已載入",
+		zap.String("dir", dir),
+		zap.Int("interactions", len(p.interactions)),
+	)
+	return p, nil
+}
+
+// Generate 錄製或回放 AI 響應
+func (p *Provider) Generate(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	recorded := p.recordRequest(req)
+	fingerprint := Fingerprint(recorded)
+
+	if p.mode == ModeReplay {
+		p.mu.RLock()
+		interaction, ok := p.interactions[fingerprint]
+		p.mu.RUnlock()
+		if !ok {
+			err := p.missError(recorded, fingerprint)
+			common.LogError("卡帶回放未命中",
+				zap.String("fingerprint", fingerprint),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+		resp := interaction.Response
+		return &resp, nil
+	}
+
+	resp, err := p.upstream.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Fingerprint: fingerprint,
+		RecordedAt:  time.Now(),
+		Request:     recorded,
+		Response:    *resp,
+	}
+	if err := p.save(interaction); err != nil {
+		// 錄製失敗不影響本次請求
+		common.LogError("卡帶寫入失敗",
+			zap.String("fingerprint", fingerprint),
+			zap.Error(err),
+		)
+	}
+
+	return resp, nil
+}
+
+// Unwrap 獲取被包裝的上游提供者，回放模式下為 nil
+func (p *Provider) Unwrap() provider.Provider {
+	return p.upstream
+}
+
+// GetModel 獲取當前使用的模型名稱
+func (p *Provider) GetModel() string {
+	return p.model
+}
+
+// GetTimeout 獲取請求超時時間
+func (p *Provider) GetTimeout() time.Duration {
+	return p.timeout
+}
+
+// Close 關閉提供者連接
+func (p *Provider) Close() error {
+	if p.upstream != nil {
+		return p.upstream.Close()
+	}
+	return nil
+}
+
+// Fingerprint 計算請求指紋：模型、生成參數、回應結構、工具定義與工具使用方式，
+// 以及各消息的角色、內容、圖片哈希與工具呼叫
+func Fingerprint(req RecordedRequest) string {
+	h := sha256.New()
+	field := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	field(req.Model)
+	field(strconv.Itoa(req.MaxTokens))
+	field(formatFloat(req.Temperature))
+	field(formatFloat(req.TopP))
+	field(strconv.Itoa(len(req.Stop)))
+	for _, stop := range req.Stop {
+		field(stop)
+	}
+	field(req.Schema)
+	field(strconv.Itoa(len(req.Tools)))
+	for _, tool := range req.Tools {
+		field(tool)
+	}
+	field(req.ToolChoice)
+
+	for _, msg := range req.Messages {
+		field(msg.Role)
+		field(msg.Content)
+		field(msg.ImageHash)
+		field(msg.ToolCallID)
+		field(strconv.Itoa(len(msg.ToolCalls)))
+		for _, call := range msg.ToolCalls {
+			field(call.ID)
+			field(call.Name)
+			field(call.Arguments)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// formatFloat 格式化選填的取樣參數，未設定時為空字串
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+// hashJSON 計算值的 JSON 編碼哈希，編碼失敗時為空字串
+func hashJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// recordRequest 將請求轉換為可錄製的形式
+func (p *Provider) recordRequest(req *provider.Request) RecordedRequest {
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	recorded := RecordedRequest{
+		Model:       model,
+		Messages:    make([]RecordedMessage, len(req.Messages)),
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stop:        req.Stop,
+		ToolChoice:  req.ToolChoice,
+	}
+	if req.Schema != nil {
+		recorded.Schema = req.Schema.Name + "@" + hashJSON(req.Schema.Schema)
+	}
+	for _, tool := range req.Tools {
+		recorded.Tools = append(recorded.Tools, tool.Name+"@"+hashJSON(tool))
+	}
+	for i, msg := range req.Messages {
+		recorded.Messages[i] = RecordedMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+		if msg.ImageData != "" {
+			hash := sha256.Sum256([]byte(msg.ImageData))
+			recorded.Messages[i].ImageHash = hex.EncodeToString(hash[:])
+		}
+	}
+	return recorded
+}
+
+// load 載入目錄中所有卡帶
+func (p *Provider) load() error {
+	files, err := filepath.Glob(filepath.Join(p.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list cassettes: %w", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read cassette %s: %w", file, err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return fmt.Errorf("failed to parse cassette %s: %w", file, err)
+		}
+		// 以內容重新計算指紋，避免手動編輯後與檔名不一致
+		interaction.Fingerprint = Fingerprint(interaction.Request)
+		p.interactions[interaction.Fingerprint] = &interaction
+	}
+	return nil
+}
+
+// save 寫入一筆錄製紀錄
+func (p *Provider) save(interaction *Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	filename := filepath.Join(p.dir, interaction.Fingerprint+".json")
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	p.mu.Lock()
+	p.interactions[interaction.Fingerprint] = interaction
+	p.mu.Unlock()
+
+	common.LogInfo("已錄製 AI 請求",
+		zap.String("filename", filename),
+		zap.String("model", interaction.Request.Model),
+	)
+	return nil
+}
+
+// missError 產生回放未命中錯誤，附上與最接近紀錄的差異
+func (p *Provider) missError(req RecordedRequest, fingerprint string) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.interactions) == 0 {
+		return fmt.Errorf("%w: fingerprint %s, no interactions recorded in %s", ErrReplayMiss, fingerprint, p.dir)
+	}
+
+	var nearest *Interaction
+	bestScore := -1
+	for _, interaction := range p.interactions {
+		score := similarity(req, interaction.Request)
+		if score > bestScore {
+			bestScore = score
+			nearest = interaction
+		}
+	}
+
+	return fmt.Errorf("%w: fingerprint %s, nearest recording %s differs:\n%s",
+		ErrReplayMiss, fingerprint, nearest.Fingerprint, diffRequests(nearest.Request, req))
+}
@@ -0,0 +1,133 @@
+package cassette
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext 差異前後保留的字元數
+const diffContext = 40
+
+// similarity 計算兩個請求的相似度（共同前後綴長度，模型與圖片相同加分）
+func similarity(a, b RecordedRequest) int {
+	score := 0
+	if a.Model == b.Model {
+		score += 1000
+	}
+	if imageHashes(a) == imageHashes(b) {
+		score += 1000
+	}
+	if settings(a) == settings(b) {
+		score += 1000
+	}
+	prefix, suffix := commonAffixes([]rune(joinContent(a)), []rune(joinContent(b)))
+	return score + prefix + suffix
+}
+
+// diffRequests 描述錄製請求 want 與實際請求 got 的差異
+func diffRequests(want, got RecordedRequest) string {
+	var lines []string
+	if want.Model != got.Model {
+		lines = append(lines, fmt.Sprintf("  model: -%s +%s", want.Model, got.Model))
+	}
+	if imageHashes(want) != imageHashes(got) {
+		lines = append(lines, fmt.Sprintf("  image_hash: -%s +%s", imageHashes(want), imageHashes(got)))
+	}
+	if settings(want) != settings(got) {
+		lines = append(lines, fmt.Sprintf("  settings: -%s +%s", settings(want), settings(got)))
+	}
+	if len(want.Messages) != len(got.Messages) {
+		lines = append(lines, fmt.Sprintf("  messages: -%d +%d", len(want.Messages), len(got.Messages)))
+	}
+
+	wantText, gotText := joinContent(want), joinContent(got)
+	if wantText != gotText {
+		lines = append(lines, "  prompt: "+diffText([]rune(wantText), []rune(gotText)))
+	}
+
+	if len(lines) == 0 {
+		return "  (no visible difference)"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffText 以共同前後綴標出差異片段，格式為 ...前文[-舊+新]後文...
+func diffText(want, got []rune) string {
+	prefix, suffix := commonAffixes(want, got)
+
+	start := prefix - diffContext
+	if start < 0 {
+		start = 0
+	}
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	sb.WriteString(string(want[start:prefix]))
+	sb.WriteString("[-")
+	sb.WriteString(string(want[prefix : len(want)-suffix]))
+	sb.WriteString("+")
+	sb.WriteString(string(got[prefix : len(got)-suffix]))
+	sb.WriteString("]")
+
+	end := len(want) - suffix + diffContext
+	if end > len(want) {
+		end = len(want)
+	}
+	sb.WriteString(string(want[len(want)-suffix : end]))
+	if end < len(want) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+// commonAffixes 計算共同前綴與（不重疊的）共同後綴長度
+func commonAffixes(a, b []rune) (int, int) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+// joinContent 串接所有消息內容與工具呼叫
+func joinContent(req RecordedRequest) string {
+	parts := make([]string, len(req.Messages))
+	for i, msg := range req.Messages {
+		part := msg.Role
+		if msg.ToolCallID != "" {
+			part += "(" + msg.ToolCallID + ")"
+		}
+		part += ": " + msg.Content
+		for _, call := range msg.ToolCalls {
+			part += fmt.Sprintf(" [%s %s(%s)]", call.ID, call.Name, call.Arguments)
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "\n")
+}
+
+// settings 描述生成參數、回應結構與工具設定
+func settings(req RecordedRequest) string {
+	return fmt.Sprintf("max_tokens=%d temperature=%s top_p=%s stop=%q schema=%s tools=%s tool_choice=%s",
+		req.MaxTokens, formatFloat(req.Temperature), formatFloat(req.TopP), req.Stop,
+		req.Schema, strings.Join(req.Tools, ","), req.ToolChoice)
+}
+
+// imageHashes 串接所有圖片哈希
+func imageHashes(req RecordedRequest) string {
+	var hashes []string
+	for _, msg := range req.Messages {
+		if msg.ImageHash != "" {
+			hashes = append(hashes, msg.ImageHash)
+		}
+	}
+	if len(hashes) == 0 {
+		return "none"
+	}
+	return strings.Join(hashes, ",")
+}
@@ -0,0 +1,129 @@
+package fallback
+
+import (
+	"sync"
+	"time"
+)
+
+// State 斷路器狀態
+type State string
+
+// 斷路器狀態
+const (
+	StateClosed   State = "closed"    // 正常放行
+	StateOpen     State = "open"      // 熔斷中，直接跳過此模型
+	StateHalfOpen State = "half_open" // 冷卻結束，放行一個試探請求
+)
+
+// BreakerStatus 斷路器狀態快照
+type BreakerStatus struct {
+	Model               string     `json:"model"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// Breaker 單一模型的斷路器
+type Breaker struct {
+	mu        sync.Mutex
+	model     string
+	threshold int
+	cooldown  time.Duration
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	lastError string
+}
+
+// NewBreaker 創建斷路器：連續失敗 threshold 次後熔斷 cooldown 時間
+func NewBreaker(model string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &Breaker{
+		model:     model,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+	}
+}
+
+// Allow 檢查是否放行請求
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		// 半開狀態同時只放行一個試探請求
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success 記錄成功，關閉斷路器
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+// Release 放行後未產生健康判斷（如非暫時性錯誤），釋放試探名額
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Failure 記錄失敗，達到門檻或試探失敗時熔斷
+func (b *Breaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Status 獲取斷路器狀態快照
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Model:               b.model,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
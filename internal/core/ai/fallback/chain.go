@@ -0,0 +1,211 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoModelAvailable 所有候選模型皆熔斷中
+var ErrNoModelAvailable = errors.New("no AI model available: all circuit breakers are open")
+
+// Config 模型降級鏈配置
+type Config struct {
+	Models           []string                   // 預設的模型順序（首個為主要模型）
+	Routes           map[provider.Task][]string // 各任務的模型順序，未設定則使用 Models
+	FailureThreshold int                        // 連續失敗幾次後熔斷
+	Cooldown         time.Duration              // 熔斷後多久進入半開試探
+}
+
+// Chain 依序嘗試多個模型的提供者，每個模型各自擁有斷路器
+type Chain struct {
+	inner    provider.Provider
+	config   Config
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+var _ provider.StreamProvider = (*Chain)(nil)
+
+// NewChain 創建模型降級鏈
+func NewChain(inner provider.Provider, cfg Config) *Chain {
+	if len(cfg.Models) == 0 {
+		cfg.Models = []string{inner.GetModel()}
+	}
+
+	c := &Chain{
+		inner:    inner,
+		config:   cfg,
+		breakers: make(map[string]*Breaker),
+	}
+
+	// 預先建立所有已知模型的斷路器，讓 /health 可完整顯示
+	for _, model := range cfg.Models {
+		c.breaker(model)
+	}
+	for _, models := range cfg.Routes {
+		for _, model := range models {
+			c.breaker(model)
+		}
+	}
+
+	return c
+}
+
+// Generate 依任務的模型順序嘗試生成，遇到限流、5xx 或超時時改用下一個模型
+func (c *Chain) Generate(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	return c.try(ctx, req, func(attempt *provider.Request) (*provider.Response, error) {
+		return c.inner.Generate(ctx, attempt)
+	}, nil)
+}
+
+// GenerateStream 以串流方式依序嘗試模型；已輸出內容後發生錯誤則不再切換模型
+func (c *Chain) GenerateStream(ctx context.Context, req *provider.Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	emitted := false
+	forward := func(delta string) error {
+		emitted = true
+		return onDelta(delta)
+	}
+
+	return c.try(ctx, req, func(attempt *provider.Request) (*provider.Response, error) {
+		if sp, ok := c.inner.(provider.StreamProvider); ok {
+			return sp.GenerateStream(ctx, attempt, forward)
+		}
+
+		// 提供者不支援串流時，將完整內容作為單一增量輸出
+		resp, err := c.inner.Generate(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if err := forward(resp.Content); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}, func() bool { return !emitted })
+}
+
+// try 依序對候選模型執行 call；canFallback 返回 false 時不再嘗試下一個模型
+func (c *Chain) try(ctx context.Context, req *provider.Request, call func(*provider.Request) (*provider.Response, error), canFallback func() bool) (*provider.Response, error) {
+	var lastErr error
+
+	for _, model := range c.modelsFor(req) {
+		b := c.breaker(model)
+		if !b.Allow() {
+			common.LogDebug("模型熔斷中，略過",
+				zap.String("model", model),
+				zap.String("task", string(req.Task)),
+			)
+			continue
+		}
+
+		attempt := *req
+		attempt.Model = model
+		resp, err := call(&attempt)
+		if err == nil {
+			b.Success()
+			if resp.Model == "" {
+				resp.Model = model
+			}
+			return resp, nil
+		}
+
+		// 呼叫端已取消或逾時，不再嘗試其他模型
+		if ctx.Err() != nil {
+			b.Release()
+			return nil, err
+		}
+
+		// 非暫時性錯誤（如請求格式錯誤）與模型健康無關，直接返回
+		if !provider.IsTransient(err) {
+			b.Release()
+			return nil, err
+		}
+
+		b.Failure(err)
+		if canFallback != nil && !canFallback() {
+			return nil, err
+		}
+
+		lastErr = err
+		common.LogWarn("模型請求失敗，改用下一個模型",
+			zap.String("model", model),
+			zap.String("task", string(req.Task)),
+			zap.Error(err),
+		)
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrNoModelAvailable
+}
+
+// BreakerStatus 獲取所有模型的斷路器狀態
+func (c *Chain) BreakerStatus() []BreakerStatus {
+	c.mu.Lock()
+	breakers := make([]*Breaker, 0, len(c.breakers))
+	for _, b := range c.breakers {
+		breakers = append(breakers, b)
+	}
+	c.mu.Unlock()
+
+	statuses := make([]BreakerStatus, len(breakers))
+	for i, b := range breakers {
+		statuses[i] = b.Status()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Model < statuses[j].Model
+	})
+	return statuses
+}
+
+// Unwrap 獲取被包裝的提供者
+func (c *Chain) Unwrap() provider.Provider {
+	return c.inner
+}
+
+// GetModel 獲取主要模型名稱
+func (c *Chain) GetModel() string {
+	return c.config.Models[0]
+}
+
+// GetTimeout 獲取請求超時時間
+func (c *Chain) GetTimeout() time.Duration {
+	return c.inner.GetTimeout()
+}
+
+// Close 關閉提供者連接
+func (c *Chain) Close() error {
+	return c.inner.Close()
+}
+
+// modelsFor 獲取請求的候選模型順序
+func (c *Chain) modelsFor(req *provider.Request) []string {
+	if req.Model != "" {
+		return []string{req.Model}
+	}
+	if models, ok := c.config.Routes[req.Task]; ok && len(models) > 0 {
+		return models
+	}
+	return c.config.Models
+}
+
+// breaker 獲取或建立模型的斷路器
+func (c *Chain) breaker(model string) *Breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.breakers[model]
+	if !ok {
+		b = NewBreaker(model, c.config.FailureThreshold, c.config.Cooldown)
+		c.breakers[model] = b
+	}
+	return b
+}
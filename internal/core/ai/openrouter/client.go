@@ -10,28 +10,75 @@ import (
 	"strings"
 	"time"
 
-	"recipe-generator/internal/core/ai/image"
-	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/core/ai/retry"
 	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
 
 	"go.uber.org/zap"
 )
 
 const (
-	baseURL = "https://openrouter.ai/api/v1"
+	baseURL        = "https://openrouter.ai/api/v1"
+	defaultTimeout = 60 * time.Second
+	// maxStreamDuration 呼叫者未設定截止時間時，單次串流請求的執行時間上限
+	maxStreamDuration = 10 * time.Minute
 )
 
-// Client OpenRouter API 客戶端
+// Client OpenRouter API 客戶端，實作 provider.Provider
+// 同一實作亦可透過 NewCompatibleClient 連接任何 OpenAI 相容端點
 type Client struct {
-	httpClient     *http.Client
-	config         *config.Config
-	imageProcessor *image.Processor
+	// httpClient 非串流請求，整個請求（含讀取響應體）受 Timeout 限制
+	httpClient *http.Client
+	// streamClient 串流請求，Timeout 只限制等待響應頭，讀取響應體的時間由 context 截止時間限制
+	streamClient *http.Client
+	config       provider.Config
+	baseURL      string
+	name         string
+	headers      map[string]string
+	// usageAccounting 是否要求回報費用（OpenRouter 的 usage.include）
+	usageAccounting bool
+	// probePath 就緒檢查使用的 GET 端點（相對於 baseURL）
+	probePath string
+	retry     *retry.Policy
 }
 
+var _ provider.Provider = (*Client)(nil)
+
 // Message 消息結構
+// Content 為純文字字串，或由 TextContent / ImageContent 組成的多模態陣列
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+// Tool 可供模型呼叫的工具
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction 工具函式定義
+type ToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  *schema.Schema `json:"parameters"`
+}
+
+// ToolCall 模型要求的工具呼叫；串流時以 Index 合併片段
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 工具呼叫的函式名稱與參數（JSON 字串）
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
 }
 
 // TextContent 文本內容
@@ -42,8 +89,13 @@ type TextContent struct {
 
 // ImageContent 圖片內容
 type ImageContent struct {
-	Type     string `json:"type"`
-	ImageURL string `json:"image_url"`
+	Type     string   `json:"type"`
+	ImageURL ImageURL `json:"image_url"`
+}
+
+// ImageURL 圖片 URL（支援 data URI）
+type ImageURL struct {
+	URL string `json:"url"`
 }
 
 // Request 表示 API 請求
@@ -51,15 +103,43 @@ type Request struct {
 	Messages         []Message       `json:"messages"`
 	Model            string          `json:"model,omitempty"`
 	MaxTokens        int             `json:"max_tokens,omitempty"`
-	Temperature      float64         `json:"temperature,omitempty"`
-	TopP             float64         `json:"top_p,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
 	TopK             int             `json:"top_k,omitempty"`
 	PresencePenalty  float64         `json:"presence_penalty,omitempty"`
 	FrequencyPenalty float64         `json:"frequency_penalty,omitempty"`
 	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *StreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
+	Tools            []Tool          `json:"tools,omitempty"`
+	ToolChoice       string          `json:"tool_choice,omitempty"`
+	Usage            *UsageOptions   `json:"usage,omitempty"`
 	Provider         *ProviderConfig `json:"provider,omitempty"`
 }
 
+// StreamOptions 串流選項
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// UsageOptions 使用量回報選項（OpenRouter usage accounting）
+type UsageOptions struct {
+	Include bool `json:"include"`
+}
+
+// ResponseFormat 結構化輸出格式
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema 結構化輸出的 JSON Schema
+type JSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema *schema.Schema `json:"schema"`
+}
+
 // ProviderConfig 表示供應商配置
 type ProviderConfig struct {
 	Only           []string `json:"only,omitempty"`
@@ -71,6 +151,7 @@ type ProviderConfig struct {
 // Response OpenRouter 響應結構
 type Response struct {
 	ID       string    `json:"id"`
+	Model    string    `json:"model"`
 	Choices  []Choice  `json:"choices"`
 	Usage    UsageInfo `json:"usage"`
 	CacheHit bool      `json:"cache_hit,omitempty"`
@@ -78,14 +159,32 @@ type Response struct {
 
 // Choice 選擇結構
 type Choice struct {
-	Message Message `json:"message"`
+	Message ResponseMessage `json:"message"`
+}
+
+// ResponseMessage 回應中的消息
+type ResponseMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
 
 // UsageInfo 使用量信息
 type UsageInfo struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost,omitempty"` // 僅在要求 usage.include 時回報（美元）
+}
+
+// toUsage 轉換為通用使用量
+func (u UsageInfo) toUsage() provider.Usage {
+	return provider.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		Cost:             u.Cost,
+	}
 }
 
 // Error 表示 API 錯誤
@@ -98,16 +197,61 @@ type Error struct {
 }
 
 // NewClient 創建新的 OpenRouter 客戶端
-func NewClient(cfg *config.Config) *Client {
+func NewClient(cfg provider.Config) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = baseURL
+	}
+	if cfg.AuthType == "" {
+		cfg.AuthType = provider.AuthBearer
+	}
+
+	c := newClient("OpenRouter", cfg)
+	c.headers["HTTP-Referer"] = "https://recipe-generator.com"
+	c.headers["X-Title"] = "Recipe Generator"
+	c.usageAccounting = true
+	c.probePath = keyInfoPath
+	return c
+}
+
+// newClient 創建客戶端並依認證方式組裝請求頭
+func newClient(name string, cfg provider.Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	if cfg.AuthType == provider.AuthBearer && cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + cfg.APIKey
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = cfg.Timeout
+
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+		},
+		streamClient: &http.Client{
+			Transport: transport,
 		},
-		config:         cfg,
-		imageProcessor: image.NewProcessor(1024), // 最大尺寸 1024 像素
+		config:    cfg,
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		name:      name,
+		headers:   headers,
+		probePath: "/models",
+		retry:     retry.NewPolicy(cfg),
 	}
 }
 
+// RetryStats 獲取上游重試統計
+func (c *Client) RetryStats() retry.Stats {
+	return c.retry.Stats()
+}
+
 // sanitizeResponse 清理響應內容，移除所有圖片數據
 func sanitizeResponse(body []byte) string {
 	// 如果是圖片數據，直接返回提示信息
@@ -177,71 +321,27 @@ func sanitizeResponse(body []byte) string {
 }
 
 // Generate 生成回應
-func (c *Client) Generate(ctx context.Context, prompt, imageData string) (*Response, error) {
+func (c *Client) Generate(ctx context.Context, pr *provider.Request) (*provider.Response, error) {
 	// 構建請求
-	req := &Request{
-		Model: c.config.OpenRouter.Model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-	}
-
-	// 如果有圖片數據，添加到請求中
-	if imageData != "" {
-		req.Messages[0].Content = fmt.Sprintf("%s\n\n圖片：%s", prompt, imageData)
-	}
-
-	// 設置模型參數
-	req.MaxTokens = 2048
-	req.Temperature = 0.7
-	req.TopP = 0.9
-	req.TopK = 40
-	req.PresencePenalty = 0.0
-	req.FrequencyPenalty = 0.0
-
-	// 檢查是否為視覺模型
-	isVisionModel := strings.Contains(strings.ToLower(req.Model), "vision") || strings.Contains(strings.ToLower(req.Model), "vl")
-	if isVisionModel {
-		req.MaxTokens = 4096
-		req.Temperature = 0.7
-		req.Stream = false
-	}
-
-	// 準備請求體
-	reqBody, err := json.Marshal(req)
+	req := c.buildRequest(pr)
+
+	var result *provider.Response
+	err := c.retry.Do(ctx, req.Model, func() error {
+		var err error
+		result, err = c.generate(ctx, req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	return result, nil
+}
 
-	// 創建 HTTP 請求
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// 設置請求頭
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.config.OpenRouter.APIKey)
-	httpReq.Header.Set("HTTP-Referer", "https://recipe-generator.com")
-	httpReq.Header.Set("X-Title", "Recipe Generator")
-
-	// 發送請求
-	common.LogInfo("Sending request to OpenRouter",
-		zap.String("model", req.Model),
-		zap.Int("messages", len(req.Messages)),
-		zap.Bool("is_vision_model", isVisionModel),
-	)
-
-	resp, err := c.httpClient.Do(httpReq)
+// generate 發送單次請求並解析響應
+func (c *Client) generate(ctx context.Context, req *Request) (*provider.Response, error) {
+	resp, err := c.send(ctx, req)
 	if err != nil {
-		common.LogError("Failed to send request to AI service",
-			zap.Error(err),
-			zap.String("model", req.Model),
-		)
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -260,12 +360,7 @@ func (c *Client) Generate(ctx context.Context, prompt, imageData string) (*Respo
 
 	// 檢查 HTTP 狀態碼
 	if resp.StatusCode != http.StatusOK {
-		common.LogError("AI service returned error status",
-			zap.Int("status_code", resp.StatusCode),
-			zap.String("model", req.Model),
-			zap.String("response", sanitizedBody),
-		)
-		return nil, fmt.Errorf("AI service error (status %d): %s", resp.StatusCode, sanitizedBody)
+		return nil, c.statusError(resp, req, sanitizedBody)
 	}
 
 	// 解析響應
@@ -276,7 +371,7 @@ func (c *Client) Generate(ctx context.Context, prompt, imageData string) (*Respo
 			zap.String("model", req.Model),
 			zap.String("response", sanitizedBody),
 		)
-		return nil, fmt.Errorf("failed to parse response: %w (response: %s)", err, sanitizedBody)
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.name, err)
 	}
 
 	// 檢查響應內容
@@ -285,26 +380,242 @@ func (c *Client) Generate(ctx context.Context, prompt, imageData string) (*Respo
 			zap.String("model", req.Model),
 			zap.String("response", sanitizedBody),
 		)
-		return nil, fmt.Errorf("empty choices in response (response: %s)", sanitizedBody)
+		// 上游模型未產生內容，視為閘道錯誤以便改用其他模型
+		return nil, &provider.Error{
+			StatusCode: http.StatusBadGateway,
+			Message:    fmt.Sprintf("no choices in %s response", c.name),
+		}
 	}
 
-	// 檢查消息內容
-	content := response.Choices[0].Message.Content
-	if len(content) == 0 {
-		common.LogError("Empty content in AI service response",
-			zap.String("model", req.Model),
-			zap.String("response", sanitizedBody),
-		)
-		return nil, fmt.Errorf("empty content in response (response: %s)", sanitizedBody)
-	}
+	message := response.Choices[0].Message
+	content := message.Content
 
 	// 記錄成功響應
 	common.LogInfo("Successfully generated response from AI service",
 		zap.String("model", req.Model),
 		zap.Int("content_length", len(content)),
+		zap.Int("tool_calls", len(message.ToolCalls)),
+	)
+
+	result := &provider.Response{
+		Content:   content,
+		Model:     response.Model,
+		ToolCalls: toToolCalls(message.ToolCalls),
+	}
+	if result.Model == "" {
+		result.Model = req.Model
+	}
+	result.Usage = response.Usage.toUsage()
+
+	return result, nil
+}
+
+// statusError 記錄並轉換非 200 響應為 provider.Error（含 Retry-After）
+func (c *Client) statusError(resp *http.Response, req *Request, sanitizedBody string) error {
+	retryAfter := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	common.LogError("AI service returned error status",
+		zap.Int("status_code", resp.StatusCode),
+		zap.String("model", req.Model),
+		zap.Duration("retry_after", retryAfter),
+		zap.String("response", sanitizedBody),
 	)
+	return &provider.Error{
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("%s API returned error (status %d): %s", c.name, resp.StatusCode, sanitizedBody),
+		RetryAfter: retryAfter,
+	}
+}
+
+// send 發送 chat/completions 請求
+func (c *Client) send(ctx context.Context, req *Request) (*http.Response, error) {
+	// 準備請求體
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// 創建 HTTP 請求
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// 設置請求頭
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		httpReq.Header.Set(k, v)
+	}
+	if req.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	// 發送請求
+	common.LogInfo("Sending request to AI service",
+		zap.String("provider", c.name),
+		zap.String("model", req.Model),
+		zap.Int("messages", len(req.Messages)),
+		zap.Bool("stream", req.Stream),
+	)
+
+	client := c.httpClient
+	if req.Stream {
+		client = c.streamClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		common.LogError("Failed to send request to AI service",
+			zap.Error(err),
+			zap.String("model", req.Model),
+		)
+		return nil, fmt.Errorf("failed to send request to %s: %w", c.name, err)
+	}
+	return resp, nil
+}
+
+// buildRequest 將通用請求轉換為 OpenRouter 請求格式
+func (c *Client) buildRequest(pr *provider.Request) *Request {
+	model := pr.Model
+	if model == "" {
+		model = c.config.Model
+	}
+
+	req := &Request{
+		Model:       model,
+		Messages:    make([]Message, 0, len(pr.Messages)),
+		MaxTokens:   pr.MaxTokens,
+		Temperature: pr.Temperature,
+		TopP:        pr.TopP,
+	}
+
+	for _, msg := range pr.Messages {
+		if msg.ImageData == "" {
+			req.Messages = append(req.Messages, Message{
+				Role:       msg.Role,
+				Content:    msg.Content,
+				ToolCalls:  fromToolCalls(msg.ToolCalls),
+				ToolCallID: msg.ToolCallID,
+			})
+			continue
+		}
+		req.Messages = append(req.Messages, Message{
+			Role: msg.Role,
+			Content: []interface{}{
+				TextContent{Type: "text", Text: msg.Content},
+				ImageContent{Type: "image_url", ImageURL: ImageURL{URL: toImageURL(msg.ImageData)}},
+			},
+		})
+	}
+
+	if c.usageAccounting {
+		req.Usage = &UsageOptions{Include: true}
+	}
+
+	if pr.Schema != nil && c.supportsSchema(model) {
+		req.ResponseFormat = &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchema{
+				Name:   pr.Schema.Name,
+				Strict: true,
+				Schema: pr.Schema.Schema,
+			},
+		}
+	}
+
+	if len(pr.Tools) > 0 && c.supportsTools(model) {
+		req.Tools = make([]Tool, len(pr.Tools))
+		for i, tool := range pr.Tools {
+			req.Tools[i] = Tool{
+				Type: "function",
+				Function: ToolFunction{
+					Name:        tool.Name,
+					Description: tool.Description,
+					Parameters:  tool.Parameters,
+				},
+			}
+		}
+		req.ToolChoice = pr.ToolChoice
+	}
+
+	return req
+}
+
+// supportsSchema 檢查模型是否支援 response_format json_schema
+func (c *Client) supportsSchema(model string) bool {
+	for _, prefix := range c.config.SchemaModels {
+		if prefix == "*" || strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// supportsTools 檢查模型是否支援 tools 工具呼叫
+func (c *Client) supportsTools(model string) bool {
+	for _, prefix := range c.config.ToolModels {
+		if prefix == "*" || strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromToolCalls 將通用工具呼叫轉換為請求格式
+func fromToolCalls(calls []provider.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		out[i] = ToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: FunctionCall{Name: call.Name, Arguments: call.Arguments},
+		}
+	}
+	return out
+}
+
+// toToolCalls 將響應中的工具呼叫轉換為通用格式
+func toToolCalls(calls []ToolCall) []provider.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]provider.ToolCall, len(calls))
+	for i, call := range calls {
+		out[i] = provider.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		}
+	}
+	return out
+}
+
+// toImageURL 將圖片資料轉換為 image_url 可接受的 URL
+func toImageURL(imageData string) string {
+	url := imageData
+	if !strings.HasPrefix(imageData, "data:image/") && !strings.HasPrefix(imageData, "http://") && !strings.HasPrefix(imageData, "https://") {
+		url = fmt.Sprintf("data:image/jpeg;base64,%s", imageData)
+	}
+
+	// debug log image_url 前 60 字元
+	preview := url
+	if len(preview) > 60 {
+		preview = preview[:60]
+	}
+	common.LogDebug("image_url debug", zap.String("image_url_start", preview))
+
+	return url
+}
+
+// GetModel 獲取當前使用的模型名稱
+func (c *Client) GetModel() string {
+	return c.config.Model
+}
 
-	return &response, nil
+// GetTimeout 獲取請求超時時間
+func (c *Client) GetTimeout() time.Duration {
+	return c.config.Timeout
 }
 
 // Close 關閉客戶端
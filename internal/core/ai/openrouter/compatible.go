@@ -0,0 +1,42 @@
+package openrouter
+
+import (
+	"fmt"
+
+	"recipe-generator/internal/core/ai/provider"
+)
+
+// NewCompatibleClient 創建連接 OpenAI 相容 /chat/completions 端點的客戶端
+// 適用於自架的視覺模型（Ollama、llama.cpp server、vLLM 等），
+// 多模態內容與 OpenRouter 相同，使用 image_url 編碼圖片。
+//
+// 認證方式由 cfg.AuthType 決定：
+//   - none：不帶任何認證頭（本機 Ollama 預設）
+//   - bearer：Authorization: Bearer <APIKey>
+//   - header：僅送出 cfg.Headers 中的自訂認證頭
+func NewCompatibleClient(cfg provider.Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("base URL is required for OpenAI-compatible provider")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required for OpenAI-compatible provider")
+	}
+
+	switch cfg.AuthType {
+	case "":
+		cfg.AuthType = provider.AuthNone
+	case provider.AuthNone, provider.AuthHeader:
+	case provider.AuthBearer:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api key is required for bearer auth")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %s", cfg.AuthType)
+	}
+
+	if cfg.AuthType == provider.AuthHeader && len(cfg.Headers) == 0 {
+		return nil, fmt.Errorf("headers are required for header auth")
+	}
+
+	return newClient("local", cfg), nil
+}
@@ -0,0 +1,68 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"recipe-generator/internal/core/ai/provider"
+)
+
+// keyInfoPath OpenRouter 查詢金鑰額度的端點
+const keyInfoPath = "/auth/key"
+
+var _ provider.Prober = (*Client)(nil)
+
+// keyInfo OpenRouter /auth/key 響應，limit_remaining 為 null 表示金鑰沒有額度上限
+type keyInfo struct {
+	Data struct {
+		Label          string   `json:"label"`
+		LimitRemaining *float64 `json:"limit_remaining"`
+	} `json:"data"`
+}
+
+// Probe 檢查上游連線與憑證：OpenRouter 查詢 /auth/key 並確認金鑰仍有額度，
+// 相容端點查詢 /models。非 200 響應轉換為 provider.Error
+func (c *Client) Probe(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+c.probePath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	for k, v := range c.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return fmt.Errorf("failed to read %s probe response: %w", c.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &provider.Error{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("%s probe returned error (status %d): %s", c.name, resp.StatusCode, sanitizeResponse(body)),
+		}
+	}
+	if c.probePath != keyInfoPath {
+		return nil
+	}
+
+	var info keyInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return fmt.Errorf("failed to parse %s key info: %w", c.name, err)
+	}
+	if info.Data.LimitRemaining != nil && *info.Data.LimitRemaining <= 0 {
+		return &provider.Error{
+			StatusCode: http.StatusPaymentRequired,
+			Message:    fmt.Sprintf("%s API key has no remaining credits", c.name),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package openrouter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/core/ai/retry"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// maxStreamLineSize 單行 SSE 資料的最大長度
+const maxStreamLineSize = 1 << 20
+
+// StreamChunk 串流響應片段（stream: true 時的 data 行）
+type StreamChunk struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+	Usage   *UsageInfo     `json:"usage,omitempty"`
+	Error   *struct {
+		Message string      `json:"message"`
+		Code    interface{} `json:"code"`
+	} `json:"error,omitempty"`
+}
+
+// StreamChoice 串流選擇結構
+type StreamChoice struct {
+	Delta        ResponseMessage `json:"delta"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+var _ provider.StreamProvider = (*Client)(nil)
+
+// GenerateStream 以串流方式生成回應，轉發每個增量內容
+func (c *Client) GenerateStream(ctx context.Context, pr *provider.Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	req := c.buildRequest(pr)
+	req.Stream = true
+	req.StreamOptions = &StreamOptions{IncludeUsage: true}
+
+	// 已輸出增量內容後不再重試，避免客戶端收到重複內容
+	emitted := false
+	forward := func(delta string) error {
+		emitted = true
+		return onDelta(delta)
+	}
+
+	var result *provider.Response
+	err := c.retry.Do(ctx, req.Model, func() error {
+		var err error
+		result, err = c.generateStream(ctx, req, forward)
+		if err != nil && emitted {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// generateStream 發送單次串流請求並轉發增量內容。串流時間可能超過 Timeout，
+// 只以 Timeout 限制等待響應頭，整個串流以呼叫者的截止時間（未設定時為 maxStreamDuration）為上限
+func (c *Client) generateStream(ctx context.Context, req *Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxStreamDuration)
+		defer cancel()
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, c.statusError(resp, req, sanitizeResponse(body))
+	}
+
+	var content strings.Builder
+	result := &provider.Response{Model: req.Model}
+	// 工具呼叫以多個片段送出，依 index 合併
+	var toolCalls []ToolCall
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// 略過空行、事件名稱與註解（如 ": OPENROUTER PROCESSING"）
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk StreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			common.LogDebug("無法解析串流片段",
+				zap.Error(err),
+				zap.String("model", req.Model),
+			)
+			continue
+		}
+		if chunk.Error != nil {
+			return nil, &provider.Error{
+				StatusCode: http.StatusBadGateway,
+				Message:    fmt.Sprintf("%s stream error: %s", c.name, chunk.Error.Message),
+			}
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage.toUsage()
+		}
+
+		for _, choice := range chunk.Choices {
+			var err error
+			if toolCalls, err = mergeToolCalls(toolCalls, choice.Delta.ToolCalls); err != nil {
+				return nil, &provider.Error{
+					StatusCode: http.StatusBadGateway,
+					Message:    fmt.Sprintf("%s stream error: %v", c.name, err),
+				}
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s stream: %w", c.name, err)
+	}
+
+	if content.Len() == 0 && len(toolCalls) == 0 {
+		return nil, &provider.Error{
+			StatusCode: http.StatusBadGateway,
+			Message:    fmt.Sprintf("no choices in %s response", c.name),
+		}
+	}
+
+	result.Content = content.String()
+	result.ToolCalls = toToolCalls(toolCalls)
+	common.LogInfo("Successfully streamed response from AI service",
+		zap.String("model", result.Model),
+		zap.Int("content_length", len(result.Content)),
+		zap.Int("tool_calls", len(result.ToolCalls)),
+	)
+	return result, nil
+}
+
+// maxStreamToolCalls 單一串流回應最多接受的工具呼叫數量，避免異常的 index 造成無上限配置
+const maxStreamToolCalls = 32
+
+// mergeToolCalls 合併串流中的工具呼叫片段：同一 index 的 ID 與名稱取首次出現者，參數依序串接；
+// index 為負數或超過 maxStreamToolCalls 時返回錯誤
+func mergeToolCalls(calls []ToolCall, deltas []ToolCall) ([]ToolCall, error) {
+	for _, delta := range deltas {
+		// 未帶 index 時，沒有 ID 的片段視為延續上一個呼叫
+		index := len(calls)
+		if delta.Index != nil {
+			index = *delta.Index
+		} else if delta.ID == "" && index > 0 {
+			index--
+		}
+		if index < 0 || index >= maxStreamToolCalls {
+			return calls, fmt.Errorf("invalid tool call index %d", index)
+		}
+		for len(calls) <= index {
+			calls = append(calls, ToolCall{})
+		}
+		call := &calls[index]
+		if call.ID == "" {
+			call.ID = delta.ID
+		}
+		if call.Function.Name == "" {
+			call.Function.Name = delta.Function.Name
+		}
+		call.Function.Arguments += delta.Function.Arguments
+	}
+	return calls, nil
+}
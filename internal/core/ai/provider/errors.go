@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Error 上游 AI 服務返回的錯誤
+type Error struct {
+	StatusCode int           // 上游 HTTP 狀態碼
+	Message    string        // 錯誤信息
+	RetryAfter time.Duration // 上游 Retry-After 要求的等待時間，未提供時為 0
+}
+
+// Error 實現 error 介面
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsTransient 判斷錯誤是否為暫時性（限流、上游故障、超時、連線中斷），可改用其他模型或重試
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if status, ok := upstreamStatus(err); ok {
+		return isTransientStatus(status)
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) || isNetworkError(err)
+}
+
+// IsRetryable 判斷同一模型是否值得重試：408、429、5xx 與連線中斷可重試；
+// 400、401、402 等請求本身的錯誤以及呼叫端取消/超時不重試
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if status, ok := upstreamStatus(err); ok {
+		return isTransientStatus(status)
+	}
+
+	// 呼叫端的 context 已結束，重試沒有意義
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	return isNetworkError(err)
+}
+
+// upstreamStatus 獲取上游錯誤的 HTTP 狀態碼
+func upstreamStatus(err error) (int, bool) {
+	var upstreamErr *Error
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.StatusCode, true
+	}
+	return 0, false
+}
+
+// isTransientStatus 判斷上游狀態碼是否為暫時性錯誤：408、429 與 5xx
+func isTransientStatus(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= http.StatusInternalServerError
+}
+
+// isNetworkError 判斷是否為連線中斷或網路超時
+func isNetworkError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
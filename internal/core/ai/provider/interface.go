@@ -3,30 +3,78 @@ package provider
 import (
 	"context"
 	"time"
+
+	"recipe-generator/internal/pkg/schema"
 )
 
 // Message 表示與 AI 模型的對話消息
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// ImageData 附加的圖片（data URI 或 base64），由提供者編碼為多模態內容
+	ImageData string `json:"image_data,omitempty"`
+	// ToolCalls assistant 訊息要求呼叫的工具
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID tool 訊息所回應的工具呼叫 ID
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ToolCall 模型要求的一次工具呼叫
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON 字串
+}
+
+// ToolDefinition 提供給模型的工具定義
+type ToolDefinition struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  *schema.Schema `json:"parameters"`
 }
 
 // Request 表示發送到 AI 提供者的請求
 type Request struct {
+	Task        Task      `json:"-"`
 	Messages    []Message `json:"messages"`
+	Model       string    `json:"model,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
-	Temperature float64   `json:"temperature,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"` // 未設定時由模型決定
+	TopP        *float64  `json:"top_p,omitempty"`
 	Stop        []string  `json:"stop,omitempty"`
+	// Schema 期望的回應結構，模型支援時以 response_format 要求結構化輸出
+	Schema *schema.Definition `json:"-"`
+	// Tools 模型可呼叫的工具，模型支援時以 tools 傳送
+	Tools []ToolDefinition `json:"-"`
+	// ToolChoice 工具使用方式（auto、none），空值由模型決定
+	ToolChoice string `json:"-"`
+	// Template 產生 prompt 的模板與版本，僅用於日誌
+	Template string `json:"-"`
 }
 
 // Response 表示從 AI 提供者收到的響應
 type Response struct {
 	Content string `json:"content"`
-	Usage   struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Model   string `json:"model"`
+	Usage   Usage  `json:"usage"`
+	// ToolCalls 模型要求呼叫的工具，不為空時 Content 可能為空
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Usage 表示單次呼叫的 token 使用量
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost,omitempty"` // 提供者回報或依單價估算的費用（美元）
+}
+
+// Add 累加使用量
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.Cost += other.Cost
 }
 
 // Provider 定義 AI 提供者介面
@@ -44,11 +92,51 @@ type Provider interface {
 	Close() error
 }
 
+// Wrapper 包裝其他提供者的提供者（降級鏈、錄製/回放等），用於逐層查詢內部狀態
+type Wrapper interface {
+	// Unwrap 獲取被包裝的提供者，沒有時返回 nil
+	Unwrap() Provider
+}
+
+// Prober 可檢查上游狀態的提供者，用於就緒檢查
+type Prober interface {
+	// Probe 以不產生模型費用的輕量請求確認上游可連線、憑證有效且仍有額度
+	Probe(ctx context.Context) error
+}
+
+// 認證方式
+const (
+	AuthNone   = "none"   // 不帶任何認證
+	AuthBearer = "bearer" // Authorization: Bearer <APIKey>
+	AuthHeader = "header" // 僅使用 Headers 中的自訂認證頭
+)
+
+// StreamHandler 接收串流增量內容，返回錯誤可中止串流
+type StreamHandler func(delta string) error
+
+// StreamProvider 支援串流輸出的 AI 提供者
+type StreamProvider interface {
+	Provider
+
+	// GenerateStream 以串流方式生成 AI 響應，每收到增量內容即呼叫 onDelta，
+	// 完成後返回完整響應
+	GenerateStream(ctx context.Context, req *Request, onDelta StreamHandler) (*Response, error)
+}
+
 // Config 定義 AI 提供者配置
 type Config struct {
 	APIKey     string
 	Model      string
 	Timeout    time.Duration
 	MaxRetries int
-	BaseURL    string
+	// RetryBaseDelay / RetryMaxDelay 重試的指數退避基本等待時間與單次上限
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	BaseURL        string
+	AuthType       string
+	Headers        map[string]string
+	// SchemaModels 支援 response_format json_schema 的模型（前綴比對，"*" 表示全部）
+	SchemaModels []string
+	// ToolModels 支援 tools 工具呼叫的模型（前綴比對，"*" 表示全部）
+	ToolModels []string
 }
@@ -0,0 +1,14 @@
+package provider
+
+// Task AI 任務類型，用於選擇模型路由
+type Task string
+
+// 預定義任務
+const (
+	TaskDefault               Task = ""
+	TaskFoodRecognition       Task = "food_recognition"
+	TaskIngredientRecognition Task = "ingredient_recognition"
+	TaskRecipeGeneration      Task = "recipe_generation"
+	TaskRecipeSuggestion      Task = "recipe_suggestion"
+	TaskChat                  Task = "chat"
+)
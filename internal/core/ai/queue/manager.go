@@ -2,116 +2,308 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
-	"sync/atomic"
+	"time"
 
-	"recipe-generator/internal/core/ai/openrouter"
 	"recipe-generator/internal/infrastructure/config"
 	"recipe-generator/internal/pkg/common"
 
 	"go.uber.org/zap"
 )
 
-// Request 隊列請求
-type Request struct {
-	Context context.Context
-	Request *openrouter.Request
-	Result  chan Result
+var (
+	// ErrQueueFull 等待中的請求已達上限
+	ErrQueueFull = errors.New("queue is full")
+	// ErrClosed 隊列已關閉
+	ErrClosed = errors.New("queue manager is closed")
+)
+
+// FullError 隊列已滿，RetryAfter 為依目前處理速度估計的建議重試等待時間
+type FullError struct {
+	RetryAfter time.Duration
+}
+
+func (e *FullError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", ErrQueueFull, e.RetryAfter)
+}
+
+// Is 讓 errors.Is(err, ErrQueueFull) 成立
+func (e *FullError) Is(target error) bool {
+	return target == ErrQueueFull
+}
+
+// Priority 請求優先級，數值越小越優先
+type Priority int
+
+// 優先級：同一優先級內先進先出，較高優先級的請求一律先處理
+const (
+	PriorityInteractive Priority = iota // 使用者即時等待的請求（例如 AR 裝置）
+	PriorityNormal                      // 一般請求
+	PriorityBackground                  // 背景工作
+	numPriorities
+)
+
+var priorityNames = [numPriorities]string{"interactive", "normal", "background"}
+
+// String 優先級名稱
+func (p Priority) String() string {
+	if p < 0 || p >= numPriorities {
+		return "unknown"
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority 解析優先級名稱（不分大小寫）
+func ParsePriority(name string) (Priority, bool) {
+	for i, n := range priorityNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return Priority(i), true
+		}
+	}
+	return PriorityNormal, false
+}
+
+// priorityKey 優先級的 context key
+type priorityKey struct{}
+
+// WithPriority 在 context 中附加請求優先級
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
 }
 
-// Result 處理結果
-type Result struct {
-	Response *openrouter.Response
-	Error    error
+// PriorityFrom 獲取 context 中的請求優先級，未設定時為 PriorityNormal
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	return PriorityNormal
 }
 
 // Status 隊列狀態
 type Status struct {
-	QueueLength    int `json:"queue_length"`
-	ProcessedCount int `json:"processed_count"`
-	MaxQueueSize   int `json:"max_queue_size"`
-	Workers        int `json:"workers"`
+	QueueLength    int            `json:"queue_length"` // 等待中的請求數
+	Active         int            `json:"active"`       // 處理中的請求數
+	Pending        map[string]int `json:"pending"`      // 各優先級等待中的請求數
+	ProcessedCount int            `json:"processed_count"`
+	RejectedCount  int            `json:"rejected_count"`
+	MaxQueueSize   int            `json:"max_queue_size"`
+	Workers        int            `json:"workers"`
+}
+
+// job 等待執行的工作
+type job struct {
+	ctx      context.Context
+	fn       func(ctx context.Context) error
+	priority Priority
+	enqueued time.Time
+	started  bool // 已由 worker 取出，受 Manager.mu 保護
+	err      error
+	done     chan struct{}
 }
 
-// Manager 隊列管理器
+// Manager 隊列管理器：以固定數量的 worker 依優先級處理請求
 type Manager struct {
-	config    *config.Config
-	queue     chan *Request
-	done      chan struct{}
-	processed int64
-	mu        sync.RWMutex
+	config *config.Config
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	pending   [numPriorities][]*job
+	length    int
+	active    int
+	processed int
+	rejected  int
+	avgRun    time.Duration // 單次執行時間的指數移動平均，用於估計重試等待時間
+	closed    bool
+
+	wg sync.WaitGroup
 }
 
-// NewManager 創建新的隊列管理器
+// NewManager 創建隊列管理器並啟動 cfg.Queue.Workers 個 worker
 func NewManager(cfg *config.Config) *Manager {
-	return &Manager{
-		config:    cfg,
-		queue:     make(chan *Request, cfg.Queue.MaxSize),
-		done:      make(chan struct{}),
-		processed: 0,
+	m := &Manager{config: cfg}
+	m.cond = sync.NewCond(&m.mu)
+	for i := 0; i < cfg.Queue.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
 	}
+	return m
 }
 
-// GetQueue 獲取請求隊列
-func (m *Manager) GetQueue() <-chan *Request {
-	return m.queue
-}
-
-// Enqueue 將請求加入隊列
-func (m *Manager) Enqueue(ctx context.Context, req *openrouter.Request) (chan Result, error) {
-	// 檢查隊列容量
-	if len(m.queue) >= m.config.Queue.MaxSize {
-		return nil, fmt.Errorf("queue is full")
+// Do 將工作依 context 中的優先級加入隊列並等待執行完成，返回工作的錯誤。
+// 等待中的請求達 cfg.Queue.MaxSize 時立即返回 *FullError；
+// context 在開始執行前取消時移出隊列並返回 context 錯誤
+func (m *Manager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	j := &job{
+		ctx:      ctx,
+		fn:       fn,
+		priority: PriorityFrom(ctx),
+		enqueued: time.Now(),
+		done:     make(chan struct{}),
 	}
 
-	// 創建隊列請求
-	queueReq := Request{
-		Context: ctx,
-		Request: req,
-		Result:  make(chan Result, 1),
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
 	}
-
-	// 加入隊列
-	select {
-	case m.queue <- &queueReq:
-		common.LogInfo("Request enqueued",
-			zap.Int("queue_length", len(m.queue)),
+	if m.length >= m.config.Queue.MaxSize {
+		m.rejected++
+		retryAfter := m.retryAfterLocked()
+		m.mu.Unlock()
+		common.LogWarn("AI 請求隊列已滿",
+			zap.String("priority", j.priority.String()),
 			zap.Int("max_queue_size", m.config.Queue.MaxSize),
+			zap.Duration("retry_after", retryAfter),
 		)
-		return queueReq.Result, nil
+		return &FullError{RetryAfter: retryAfter}
+	}
+	m.pending[j.priority] = append(m.pending[j.priority], j)
+	m.length++
+	length := m.length
+	m.cond.Signal()
+	m.mu.Unlock()
+
+	common.LogDebug("Request enqueued",
+		zap.String("priority", j.priority.String()),
+		zap.Int("queue_length", length),
+		zap.Int("max_queue_size", m.config.Queue.MaxSize),
+	)
+
+	select {
+	case <-j.done:
+		return j.err
 	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-m.done:
-		return nil, fmt.Errorf("queue manager is closed")
 	}
+
+	m.mu.Lock()
+	if !j.started {
+		m.remove(j)
+		m.mu.Unlock()
+		return ctx.Err()
+	}
+	m.mu.Unlock()
+
+	// 已開始執行，工作會隨 context 取消而結束
+	<-j.done
+	return j.err
+}
+
+// remove 將尚未執行的工作移出隊列，呼叫端需持有 m.mu
+func (m *Manager) remove(j *job) {
+	queue := m.pending[j.priority]
+	for i, q := range queue {
+		if q == j {
+			m.pending[j.priority] = append(queue[:i], queue[i+1:]...)
+			m.length--
+			return
+		}
+	}
+}
+
+// worker 依優先級取出工作並執行
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for {
+		m.mu.Lock()
+		for m.length == 0 && !m.closed {
+			m.cond.Wait()
+		}
+		if m.length == 0 && m.closed {
+			m.mu.Unlock()
+			return
+		}
+		j := m.next()
+		j.started = true
+		m.active++
+		m.mu.Unlock()
+
+		wait := time.Since(j.enqueued)
+		start := time.Now()
+		j.err = j.fn(j.ctx)
+		elapsed := time.Since(start)
+
+		m.mu.Lock()
+		m.active--
+		m.processed++
+		if m.avgRun == 0 {
+			m.avgRun = elapsed
+		} else {
+			m.avgRun = (m.avgRun*4 + elapsed) / 5
+		}
+		m.mu.Unlock()
+		close(j.done)
+
+		common.LogDebug("Request processed",
+			zap.String("priority", j.priority.String()),
+			zap.Duration("queue_wait", wait),
+			zap.Duration("duration", elapsed),
+		)
+	}
+}
+
+// next 取出優先級最高、最早加入的工作，呼叫端需持有 m.mu 且隊列不為空
+func (m *Manager) next() *job {
+	for p := range m.pending {
+		if queue := m.pending[p]; len(queue) > 0 {
+			j := queue[0]
+			queue[0] = nil
+			m.pending[p] = queue[1:]
+			m.length--
+			return j
+		}
+	}
+	panic("queue: next called on empty queue")
+}
+
+// retryAfterLocked 估計隊列消化所需時間：等待數 / worker 數 × 平均執行時間，至少 1 秒。
+// 呼叫端需持有 m.mu
+func (m *Manager) retryAfterLocked() time.Duration {
+	workers := m.config.Queue.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	estimate := time.Duration(math.Ceil(float64(m.length)/float64(workers))) * m.avgRun
+	if estimate < time.Second {
+		return time.Second
+	}
+	return estimate.Round(time.Second)
 }
 
 // GetQueueStatus 獲取隊列狀態
 func (m *Manager) GetQueueStatus() *Status {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	pending := make(map[string]int, numPriorities)
+	for p, queue := range m.pending {
+		pending[Priority(p).String()] = len(queue)
+	}
 	return &Status{
-		QueueLength:    len(m.queue),
-		ProcessedCount: int(m.processed),
+		QueueLength:    m.length,
+		Active:         m.active,
+		Pending:        pending,
+		ProcessedCount: m.processed,
+		RejectedCount:  m.rejected,
 		MaxQueueSize:   m.config.Queue.MaxSize,
 		Workers:        m.config.Queue.Workers,
 	}
 }
 
-// IncrementProcessed 增加處理計數
-func (m *Manager) IncrementProcessed() {
-	atomic.AddInt64(&m.processed, 1)
-}
-
-// Done 關閉隊列管理器
-func (m *Manager) Done() {
-	close(m.done)
-}
-
-// Close 關閉隊列管理器
+// Close 停止接受新請求，等待已加入的請求處理完畢後結束 worker
 func (m *Manager) Close() {
-	close(m.done)
-	close(m.queue)
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.cond.Broadcast()
+	m.mu.Unlock()
+
+	m.wg.Wait()
 }
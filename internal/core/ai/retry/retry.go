@@ -0,0 +1,208 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// 預設退避參數
+const (
+	DefaultBaseDelay = 500 * time.Millisecond
+	DefaultMaxDelay  = 10 * time.Second
+)
+
+// Stats 重試統計
+type Stats struct {
+	Calls         int64 `json:"calls"`          // 呼叫次數（不含重試）
+	Retries       int64 `json:"retries"`        // 重試次數
+	Recovered     int64 `json:"recovered"`      // 重試後成功的呼叫
+	Exhausted     int64 `json:"exhausted"`      // 用盡重試次數仍失敗的呼叫
+	DeadlineStops int64 `json:"deadline_stops"` // 因剩餘時間不足而停止重試的呼叫
+}
+
+// Policy 上游重試策略：指數退避加隨機抖動，並遵守 Retry-After
+type Policy struct {
+	MaxRetries int           // 最多重試次數，0 表示不重試
+	BaseDelay  time.Duration // 第一次重試前的基本等待時間
+	MaxDelay   time.Duration // 單次等待上限；Retry-After 超過此值時不重試，交由降級鏈改用其他模型
+
+	calls         atomic.Int64
+	retries       atomic.Int64
+	recovered     atomic.Int64
+	exhausted     atomic.Int64
+	deadlineStops atomic.Int64
+}
+
+// NewPolicy 依提供者設定創建重試策略
+func NewPolicy(cfg provider.Config) *Policy {
+	p := &Policy{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  cfg.RetryBaseDelay,
+		MaxDelay:   cfg.RetryMaxDelay,
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+	return p
+}
+
+// permanentError 標記不應重試的錯誤
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 標記錯誤不可重試（例如串流已輸出部分內容）
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do 執行 fn，遇到可重試的錯誤時依策略等待後重試；name 用於日誌
+func (p *Policy) Do(ctx context.Context, name string, fn func() error) error {
+	p.calls.Add(1)
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		err := fn()
+		if err == nil {
+			if attempt > 0 {
+				p.recovered.Add(1)
+			}
+			return nil
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if !provider.IsRetryable(err) {
+			return err
+		}
+		if attempt >= p.MaxRetries {
+			if p.MaxRetries > 0 {
+				p.exhausted.Add(1)
+				common.LogWarn("AI 上游重試次數已用盡",
+					zap.String("model", name),
+					zap.Int("attempts", attempt+1),
+					zap.Error(err),
+				)
+			}
+			return err
+		}
+
+		delay, ok := p.delay(attempt, err)
+		if !ok {
+			common.LogWarn("AI 上游要求的等待時間過長，不再重試",
+				zap.String("model", name),
+				zap.Duration("retry_after", retryAfter(err)),
+				zap.Error(err),
+			)
+			return err
+		}
+
+		// 剩餘時間不足以等待並完成下一次嘗試時停止
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+time.Since(start) {
+			p.deadlineStops.Add(1)
+			common.LogWarn("剩餘時間不足，停止重試 AI 上游",
+				zap.String("model", name),
+				zap.Int("attempts", attempt+1),
+				zap.Duration("remaining", time.Until(deadline)),
+				zap.Error(err),
+			)
+			return err
+		}
+
+		p.retries.Add(1)
+		common.LogWarn("AI 上游請求失敗，稍後重試",
+			zap.String("model", name),
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_retries", p.MaxRetries),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay 計算第 attempt 次失敗後的等待時間；Retry-After 超過上限時返回 false
+func (p *Policy) delay(attempt int, err error) (time.Duration, bool) {
+	if ra := retryAfter(err); ra > 0 {
+		if ra > p.MaxDelay {
+			return 0, false
+		}
+		return ra, true
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	// 等距抖動：在 [backoff/2, backoff] 之間隨機，避免多個請求同時重試
+	half := backoff / 2
+	return half + rand.N(half+1), true
+}
+
+// retryAfter 獲取上游要求的等待時間
+func retryAfter(err error) time.Duration {
+	var upstreamErr *provider.Error
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.RetryAfter
+	}
+	return 0
+}
+
+// Stats 獲取重試統計
+func (p *Policy) Stats() Stats {
+	return Stats{
+		Calls:         p.calls.Load(),
+		Retries:       p.retries.Load(),
+		Recovered:     p.recovered.Load(),
+		Exhausted:     p.exhausted.Load(),
+		DeadlineStops: p.deadlineStops.Load(),
+	}
+}
+
+// ParseRetryAfter 解析 Retry-After 標頭（秒數或 HTTP 日期）
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/core/ai/tools"
+)
+
+// 快取狀態，回寫至 X-Cache 響應頭
+const (
+	CacheHit   = "HIT"   // 未超過 CACHE_TTL 的快取值
+	CacheStale = "STALE" // 超過 CACHE_TTL 的舊值，已於背景重新生成
+	CacheMiss  = "MISS"  // 未命中，由 AI 提供者生成
+)
+
+// callInfoKey CallInfo 的 context key
+type callInfoKey struct{}
+
+// CallInfo 單一 HTTP 請求內的 AI 呼叫資訊，供 handler 回寫至響應頭
+type CallInfo struct {
+	mu          sync.Mutex
+	requestID   string
+	models      []string
+	repairs     int
+	calls       int
+	usage       provider.Usage
+	sharedUse   provider.Usage // 合併請求中計入其他請求的使用量，只回寫至響應頭
+	sharedCalls int
+	toolCalls   []tools.Trace
+	templates   []string
+	cache       []string
+}
+
+// WithCallInfo 在 context 中附加新的 CallInfo
+func WithCallInfo(ctx context.Context) (context.Context, *CallInfo) {
+	info := &CallInfo{}
+	return context.WithValue(ctx, callInfoKey{}, info), info
+}
+
+// CallInfoFrom 從 context 獲取 CallInfo，不存在時返回 nil
+func CallInfoFrom(ctx context.Context) *CallInfo {
+	info, _ := ctx.Value(callInfoKey{}).(*CallInfo)
+	return info
+}
+
+// SetRequestID 設定請求 ID，用於 AI 呼叫日誌
+func (ci *CallInfo) SetRequestID(id string) {
+	if ci == nil {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.requestID = id
+}
+
+// RequestID 獲取請求 ID
+func (ci *CallInfo) RequestID() string {
+	if ci == nil {
+		return ""
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return ci.requestID
+}
+
+// Models 獲取實際回應的模型（依呼叫順序，不重複）
+func (ci *CallInfo) Models() []string {
+	if ci == nil {
+		return nil
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return append([]string(nil), ci.models...)
+}
+
+// recordModel 記錄實際回應的模型
+func (ci *CallInfo) recordModel(model string) {
+	if ci == nil || model == "" {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	for _, m := range ci.models {
+		if m == model {
+			return
+		}
+	}
+	ci.models = append(ci.models, model)
+}
+
+// Repairs 獲取因回應不符結構而要求模型修正的次數
+func (ci *CallInfo) Repairs() int {
+	if ci == nil {
+		return 0
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return ci.repairs
+}
+
+// recordRepair 記錄一次結構修正
+func (ci *CallInfo) recordRepair() {
+	if ci == nil {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.repairs++
+}
+
+// Usage 獲取本次請求所有 AI 呼叫的累計使用量與呼叫次數，包含合併請求中計入其他請求的部分
+func (ci *CallInfo) Usage() (provider.Usage, int) {
+	if ci == nil {
+		return provider.Usage{}, 0
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	usage := ci.usage
+	usage.Add(ci.sharedUse)
+	return usage, ci.calls + ci.sharedCalls
+}
+
+// BilledUsage 獲取計入本次請求的使用量與呼叫次數，用於使用量統計
+func (ci *CallInfo) BilledUsage() (provider.Usage, int) {
+	if ci == nil {
+		return provider.Usage{}, 0
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return ci.usage, ci.calls
+}
+
+// recordUsage 累加一次 AI 呼叫的使用量
+func (ci *CallInfo) recordUsage(u provider.Usage) {
+	if ci == nil {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.calls++
+	ci.usage.Add(u)
+}
+
+// Templates 獲取本次請求使用的 prompt 模板與版本（依使用順序，不重複）
+func (ci *CallInfo) Templates() []string {
+	if ci == nil {
+		return nil
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return append([]string(nil), ci.templates...)
+}
+
+// recordTemplate 記錄使用的 prompt 模板
+func (ci *CallInfo) recordTemplate(template string) {
+	if ci == nil || template == "" {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	for _, t := range ci.templates {
+		if t == template {
+			return
+		}
+	}
+	ci.templates = append(ci.templates, template)
+}
+
+// CacheStatus 獲取本次請求查詢快取的結果（依查詢順序，不重複），未查詢快取時為空
+func (ci *CallInfo) CacheStatus() []string {
+	if ci == nil {
+		return nil
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return append([]string(nil), ci.cache...)
+}
+
+// recordCache 記錄一次快取查詢結果
+func (ci *CallInfo) recordCache(status string) {
+	if ci == nil {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	for _, s := range ci.cache {
+		if s == status {
+			return
+		}
+	}
+	ci.cache = append(ci.cache, status)
+}
+
+// ToolCalls 獲取本次請求執行過的工具呼叫紀錄
+func (ci *CallInfo) ToolCalls() []tools.Trace {
+	if ci == nil {
+		return nil
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	return append([]tools.Trace(nil), ci.toolCalls...)
+}
+
+// recordToolCall 記錄一次工具呼叫
+func (ci *CallInfo) recordToolCall(trace tools.Trace) {
+	if ci == nil {
+		return
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.toolCalls = append(ci.toolCalls, trace)
+}
+
+// adopt 複製合併請求中上游呼叫的模型、結構修正、工具呼叫與使用量；
+// billed 為 false 時使用量只回寫至響應頭，不計入本次請求
+func (ci *CallInfo) adopt(from *CallInfo, billed bool) {
+	if ci == nil || from == nil {
+		return
+	}
+	from.mu.Lock()
+	models := append([]string(nil), from.models...)
+	repairs := from.repairs
+	usage, calls := from.usage, from.calls
+	toolCalls := append([]tools.Trace(nil), from.toolCalls...)
+	from.mu.Unlock()
+
+	for _, model := range models {
+		ci.recordModel(model)
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.repairs += repairs
+	ci.toolCalls = append(ci.toolCalls, toolCalls...)
+	if billed {
+		ci.calls += calls
+		ci.usage.Add(usage)
+	} else {
+		ci.sharedCalls += calls
+		ci.sharedUse.Add(usage)
+	}
+}
+
+// FormatUsage 將使用量格式化為 X-AI-Usage 響應頭的值
+func FormatUsage(u provider.Usage) string {
+	return fmt.Sprintf("prompt_tokens=%d; completion_tokens=%d; total_tokens=%d; cost=%.6f",
+		u.PromptTokens, u.CompletionTokens, u.TotalTokens, u.Cost)
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// flightGroup 合併進行中的相同請求：第一個呼叫者發出上游呼叫，
+// 之後的呼叫者等待同一個結果，不重複呼叫上游
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight 進行中的上游呼叫。鎖的順序固定為 flightGroup.mu 再 flight.mu，
+// 且持有任何一個鎖時都不寫入客戶端
+type flight struct {
+	key     string
+	mu      sync.Mutex
+	nextID  int
+	waiters map[int]chan struct{} // 等待者與其通知通道，串流等待者有新的增量內容時觸發，其他為 nil
+	content strings.Builder       // 已輸出的增量內容，各串流等待者依自己的進度讀取
+	stream  bool                  // 上游呼叫是否以串流進行，由第一個呼叫者決定
+	info    *CallInfo             // 上游呼叫的模型、使用量與工具呼叫，完成後複製給各等待者
+	payer   int                   // 計入使用量的等待者，完成時仍在等待者中最早加入者，-1 表示無
+
+	// 上游呼叫的 context 狀態，見 flightContext
+	deadline time.Time // 等待者中最晚的截止時間，零值表示至少一個等待者沒有截止時間
+	timer    *time.Timer
+	ctxDone  chan struct{}
+	ctxErr   error
+
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// newFlightGroup 創建請求合併群組
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[string]*flight)}
+}
+
+// do 以 key 合併相同請求並等待結果。上游呼叫不受個別呼叫者取消影響，
+// 截止時間為等待者中最晚者，所有等待者都離開後才會取消；串流呼叫者會收到已輸出的內容與之後的增量，
+// 若上游呼叫並非串流則在完成時收到完整內容
+func (g *flightGroup) do(ctx context.Context, key string, onDelta provider.StreamHandler, fn func(ctx context.Context, onDelta provider.StreamHandler) (*Response, error)) (*Response, error) {
+	g.mu.Lock()
+	f, shared := g.flights[key]
+	if !shared {
+		f = newFlight(key, onDelta != nil)
+		g.flights[key] = f
+	}
+	// 先加入再發出呼叫，上游呼叫開始時即帶有第一個呼叫者的截止時間
+	id, notify := f.join(ctx, onDelta != nil)
+	if !shared {
+		g.start(ctx, f, fn)
+	}
+	g.mu.Unlock()
+
+	if shared {
+		common.LogInfo("合併進行中的相同 AI 請求",
+			zap.String("request_id", CallInfoFrom(ctx).RequestID()),
+		)
+	}
+
+	// 依自己的進度在鎖外寫入增量內容，慢速的呼叫者不影響上游讀取與其他呼叫者
+	sent := 0
+	flush := func() error {
+		if chunk := f.pending(&sent); chunk != "" {
+			return onDelta(chunk)
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-notify:
+			if err := flush(); err != nil {
+				g.leave(f, id)
+				return nil, err
+			}
+		case <-f.done:
+			g.leave(f, id)
+			// 模型、使用量與工具呼叫複製給每個等待者，使用量只計入一個等待者
+			CallInfoFrom(ctx).adopt(f.info, id == f.payer)
+			if f.err != nil {
+				return nil, f.err
+			}
+			if onDelta != nil {
+				var err error
+				if f.stream {
+					err = flush()
+				} else {
+					err = onDelta(f.resp.Content)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			resp := *f.resp
+			return &resp, nil
+		case <-ctx.Done():
+			g.leave(f, id)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// newFlight 創建上游呼叫
+func newFlight(key string, stream bool) *flight {
+	return &flight{
+		key:     key,
+		waiters: make(map[int]chan struct{}),
+		stream:  stream,
+		payer:   -1,
+		ctxDone: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start 發出上游呼叫；呼叫端需持有 g.mu。上游呼叫保留第一個呼叫者的請求 ID 與其他 context 值，
+// 模型與使用量記錄於 flight 自己的 CallInfo
+func (g *flightGroup) start(ctx context.Context, f *flight, fn func(ctx context.Context, onDelta provider.StreamHandler) (*Response, error)) {
+	callCtx, info := WithCallInfo(context.WithoutCancel(ctx))
+	info.SetRequestID(CallInfoFrom(ctx).RequestID())
+	f.info = info
+
+	var onDelta provider.StreamHandler
+	if f.stream {
+		onDelta = f.broadcast
+	}
+	go func() {
+		defer f.stop(context.Canceled)
+		resp, err := fn(&flightContext{Context: callCtx, f: f}, onDelta)
+
+		// 先移除再通知，之後的相同請求會重新呼叫（或命中快取）；
+		// 已被取消的呼叫可能已由新的呼叫取代，只移除自己
+		g.mu.Lock()
+		if g.flights[f.key] == f {
+			delete(g.flights, f.key)
+		}
+		g.mu.Unlock()
+
+		f.mu.Lock()
+		for id := range f.waiters {
+			if f.payer < 0 || id < f.payer {
+				f.payer = id
+			}
+		}
+		f.mu.Unlock()
+		if f.payer < 0 {
+			usage, calls := info.Usage()
+			common.LogWarn("合併的 AI 請求完成時已無等待者，使用量不計入任何客戶端",
+				zap.String("request_id", info.RequestID()),
+				zap.Int("ai_calls", calls),
+				zap.Int("total_tokens", usage.TotalTokens),
+				zap.Float64("cost", usage.Cost),
+			)
+		}
+
+		f.resp, f.err = resp, err
+		close(f.done)
+	}()
+}
+
+// leave 離開等待；最後一個等待者在上游呼叫完成前離開時取消上游呼叫，
+// 並立即自群組移除，之後的相同請求會發出新的呼叫而不是加入已取消的呼叫
+func (g *flightGroup) leave(f *flight, id int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.waiters, id)
+	if len(f.waiters) > 0 {
+		return
+	}
+	select {
+	case <-f.done:
+	default:
+		f.cancelLocked(context.Canceled)
+		if g.flights[f.key] == f {
+			delete(g.flights, f.key)
+		}
+	}
+}
+
+// join 加入等待並依呼叫者的截止時間延後上游呼叫的截止時間；
+// 串流呼叫者在串流進行的呼叫上會取得通知通道，已有輸出內容時立即觸發通知
+func (f *flight) join(ctx context.Context, stream bool) (int, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.nextID
+	f.nextID++
+
+	deadline, ok := ctx.Deadline()
+	switch {
+	case !ok:
+		// 沒有截止時間的等待者，上游呼叫只在所有等待者離開後取消
+		f.deadline = time.Time{}
+		if f.timer != nil {
+			f.timer.Stop()
+		}
+	case id == 0 || (!f.deadline.IsZero() && deadline.After(f.deadline)):
+		f.deadline = deadline
+		if f.timer == nil {
+			f.timer = time.AfterFunc(time.Until(deadline), f.expire)
+		} else {
+			f.timer.Reset(time.Until(deadline))
+		}
+	}
+
+	var ch chan struct{}
+	if stream && f.stream {
+		ch = make(chan struct{}, 1)
+		if f.content.Len() > 0 {
+			ch <- struct{}{}
+		}
+	}
+	f.waiters[id] = ch
+	return id, ch
+}
+
+// expire 截止時間到達時取消上游呼叫；截止時間已被延後時忽略
+func (f *flight) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.deadline.IsZero() || time.Now().Before(f.deadline) {
+		return
+	}
+	f.cancelLocked(context.DeadlineExceeded)
+}
+
+// stop 結束上游呼叫的 context
+func (f *flight) stop(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cancelLocked(err)
+}
+
+// cancelLocked 以 err 結束上游呼叫的 context；呼叫端需持有 f.mu
+func (f *flight) cancelLocked(err error) {
+	if f.ctxErr != nil {
+		return
+	}
+	f.ctxErr = err
+	close(f.ctxDone)
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+}
+
+// pending 獲取 sent 之後尚未送出的內容並更新進度
+func (f *flight) pending(sent *int) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	chunk := f.content.String()[*sent:]
+	*sent = f.content.Len()
+	return chunk
+}
+
+// broadcast 記錄增量內容並通知所有串流呼叫者，不等待呼叫者寫入
+func (f *flight) broadcast(delta string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.content.WriteString(delta)
+	for _, ch := range f.waiters {
+		if ch == nil {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// flightContext 上游呼叫的 context：保留第一個呼叫者的 context 值，不隨個別呼叫者取消；
+// 截止時間為目前等待者中最晚者，後加入的等待者可延後，重試與備援據此判斷剩餘時間
+type flightContext struct {
+	context.Context
+	f *flight
+}
+
+// Deadline 獲取目前的截止時間
+func (c *flightContext) Deadline() (time.Time, bool) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+
+	return c.f.deadline, !c.f.deadline.IsZero()
+}
+
+// Done 所有等待者離開、截止時間到達或呼叫結束時關閉
+func (c *flightContext) Done() <-chan struct{} {
+	return c.f.ctxDone
+}
+
+// Err 獲取結束原因
+func (c *flightContext) Err() error {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+
+	return c.f.ctxErr
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// TestFlightFollowerExtendsDeadline 第一個呼叫者的截止時間較短時，上游呼叫依較晚加入的等待者延後截止時間
+func TestFlightFollowerExtendsDeadline(t *testing.T) {
+	common.Logger = zap.NewNop()
+	g := newFlightGroup()
+	joined := make(chan struct{})
+	deadlines := make(chan time.Time, 2)
+
+	fn := func(ctx context.Context, _ provider.StreamHandler) (*Response, error) {
+		d, _ := ctx.Deadline()
+		deadlines <- d
+		<-joined
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		d, _ = ctx.Deadline()
+		deadlines <- d
+		CallInfoFrom(ctx).recordModel("test/model")
+		CallInfoFrom(ctx).recordUsage(provider.Usage{TotalTokens: 10})
+		return &Response{Content: "ok", Model: "test/model"}, nil
+	}
+
+	leaderCtx, leaderInfo := WithCallInfo(context.Background())
+	leaderCtx, cancelLeader := context.WithTimeout(leaderCtx, 50*time.Millisecond)
+	defer cancelLeader()
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := g.do(leaderCtx, "k", nil, fn)
+		leaderErr <- err
+	}()
+
+	leaderDeadline := <-deadlines
+	if want, _ := leaderCtx.Deadline(); !leaderDeadline.Equal(want) {
+		t.Fatalf("initial deadline = %v, want leader deadline %v", leaderDeadline, want)
+	}
+
+	followerCtx, followerInfo := WithCallInfo(context.Background())
+	followerCtx, cancelFollower := context.WithTimeout(followerCtx, 2*time.Second)
+	defer cancelFollower()
+	followerResp := make(chan *Response, 1)
+	followerErr := make(chan error, 1)
+	go func() {
+		resp, err := g.do(followerCtx, "k", nil, fn)
+		followerResp <- resp
+		followerErr <- err
+	}()
+	for {
+		g.mu.Lock()
+		f := g.flights["k"]
+		f.mu.Lock()
+		n := len(f.waiters)
+		f.mu.Unlock()
+		g.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(joined)
+
+	if err := <-leaderErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("leader error = %v, want deadline exceeded", err)
+	}
+	if err := <-followerErr; err != nil {
+		t.Fatalf("follower error = %v, want shared result", err)
+	}
+	if resp := <-followerResp; resp.Content != "ok" {
+		t.Fatalf("follower content = %q, want %q", resp.Content, "ok")
+	}
+	if d, want := <-deadlines, mustDeadline(t, followerCtx); !d.Equal(want) {
+		t.Fatalf("extended deadline = %v, want follower deadline %v", d, want)
+	}
+
+	// 第一個呼叫者已離開，使用量計入完成時仍在等待的呼叫者
+	if models := followerInfo.Models(); len(models) != 1 || models[0] != "test/model" {
+		t.Fatalf("follower models = %v, want [test/model]", models)
+	}
+	if usage, calls := followerInfo.BilledUsage(); calls != 1 || usage.TotalTokens != 10 {
+		t.Fatalf("follower billed usage = %+v (%d calls), want 10 tokens in 1 call", usage, calls)
+	}
+	if _, calls := leaderInfo.BilledUsage(); calls != 0 {
+		t.Fatalf("leader billed calls = %d, want 0", calls)
+	}
+}
+
+// mustDeadline 獲取 ctx 的截止時間
+func mustDeadline(t *testing.T, ctx context.Context) time.Time {
+	t.Helper()
+	d, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("context has no deadline")
+	}
+	return d
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"recipe-generator/internal/core/ai/cassette"
+	"recipe-generator/internal/core/ai/fallback"
+	"recipe-generator/internal/core/ai/openrouter"
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/infrastructure/config"
+)
+
+// NewProvider 依設定建立 AI 提供者，並包裝為帶斷路器的模型降級鏈
+func NewProvider(cfg *config.Config) (provider.Provider, error) {
+	p, err := newProvider(cfg, cfg.AI.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[provider.Task][]string)
+	for task, route := range cfg.Routing.Routes() {
+		if models := compactModels(route.Models); len(models) > 0 {
+			routes[provider.Task(task)] = models
+		}
+	}
+
+	return fallback.NewChain(p, fallback.Config{
+		Models:           compactModels(append([]string{p.GetModel()}, cfg.AI.FallbackModels...)),
+		Routes:           routes,
+		FailureThreshold: cfg.AI.BreakerThreshold,
+		Cooldown:         cfg.AI.BreakerCooldown,
+	}), nil
+}
+
+// newProvider 依名稱建立 AI 提供者
+func newProvider(cfg *config.Config, name string) (provider.Provider, error) {
+	switch strings.ToLower(name) {
+	case "", "openrouter":
+		return openrouter.NewClient(provider.Config{
+			APIKey:         cfg.OpenRouter.APIKey,
+			Model:          cfg.OpenRouter.Model,
+			Timeout:        cfg.OpenRouter.Timeout,
+			SchemaModels:   cfg.AI.SchemaModels,
+			ToolModels:     cfg.AI.ToolModels,
+			MaxRetries:     cfg.AI.MaxRetries,
+			RetryBaseDelay: cfg.AI.RetryBaseDelay,
+			RetryMaxDelay:  cfg.AI.RetryMaxDelay,
+		}), nil
+	case "local":
+		return openrouter.NewCompatibleClient(provider.Config{
+			BaseURL:        cfg.Local.BaseURL,
+			Model:          cfg.Local.Model,
+			APIKey:         cfg.Local.APIKey,
+			AuthType:       strings.ToLower(cfg.Local.AuthType),
+			Headers:        cfg.Local.HeaderMap(),
+			Timeout:        cfg.Local.Timeout,
+			SchemaModels:   cfg.AI.SchemaModels,
+			ToolModels:     cfg.AI.ToolModels,
+			MaxRetries:     cfg.AI.MaxRetries,
+			RetryBaseDelay: cfg.AI.RetryBaseDelay,
+			RetryMaxDelay:  cfg.AI.RetryMaxDelay,
+		})
+	case cassette.ModeRecord:
+		if isCassetteMode(cfg.Cassette.Upstream) {
+			return nil, fmt.Errorf("invalid cassette upstream: %s", cfg.Cassette.Upstream)
+		}
+		upstream, err := newProvider(cfg, cfg.Cassette.Upstream)
+		if err != nil {
+			return nil, err
+		}
+		return cassette.NewRecorder(upstream, cfg.Cassette.Dir)
+	case cassette.ModeReplay:
+		return cassette.NewReplayer(cfg.Cassette.Dir, upstreamModel(cfg))
+	default:
+		return nil, fmt.Errorf("unsupported AI provider: %s", name)
+	}
+}
+
+// compactModels 去除空白與重複的模型名稱，保留順序
+func compactModels(models []string) []string {
+	seen := make(map[string]bool, len(models))
+	result := make([]string, 0, len(models))
+	for _, model := range models {
+		model = strings.TrimSpace(model)
+		if model == "" || seen[model] {
+			continue
+		}
+		seen[model] = true
+		result = append(result, model)
+	}
+	return result
+}
+
+// isCassetteMode 檢查名稱是否為錄製/回放模式
+func isCassetteMode(name string) bool {
+	name = strings.ToLower(name)
+	return name == cassette.ModeRecord || name == cassette.ModeReplay
+}
+
+// upstreamModel 獲取錄製時上游提供者的預設模型，確保回放指紋一致
+func upstreamModel(cfg *config.Config) string {
+	if strings.EqualFold(cfg.Cassette.Upstream, "local") {
+		return cfg.Local.Model
+	}
+	return cfg.OpenRouter.Model
+}
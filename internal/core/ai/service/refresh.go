@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// refreshGroup 記錄進行中的背景更新，同一個鍵同時只更新一次
+type refreshGroup struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// newRefreshGroup 創建背景更新群組
+func newRefreshGroup() *refreshGroup {
+	return &refreshGroup{keys: make(map[string]struct{})}
+}
+
+// acquire 標記 key 開始更新，已在更新時返回 false
+func (g *refreshGroup) acquire(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.keys[key]; ok {
+		return false
+	}
+	g.keys[key] = struct{}{}
+	return true
+}
+
+// release 標記 key 更新結束
+func (g *refreshGroup) release(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.keys, key)
+}
+
+// revalidate 在背景以 fn 重新生成超過 CACHE_TTL 的快取值，同一個鍵同時只執行一次；
+// 背景呼叫不隨原請求取消，使用量記錄於獨立的 CallInfo，不計入原請求
+func (s *Service) revalidate(ctx context.Context, task provider.Task, key string, fn func(ctx context.Context, onDelta provider.StreamHandler) (*Response, error)) {
+	if !s.refreshes.acquire(key) {
+		return
+	}
+
+	requestID := CallInfoFrom(ctx).RequestID()
+	refreshCtx, info := WithCallInfo(context.WithoutCancel(ctx))
+	info.SetRequestID(requestID)
+	refreshCtx, cancel := context.WithTimeout(refreshCtx, s.config.Cache.RefreshTimeout)
+
+	common.LogInfo("快取已超過存活時間，回傳舊值並於背景更新",
+		zap.String("request_id", requestID),
+		zap.String("task", string(task)),
+	)
+
+	go func() {
+		defer s.refreshes.release(key)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := fn(refreshCtx, nil); err != nil {
+			common.LogWarn("背景更新快取失敗，保留舊值",
+				zap.String("request_id", requestID),
+				zap.String("task", string(task)),
+				zap.Duration("duration", time.Since(start)),
+				zap.Error(err),
+			)
+			return
+		}
+
+		usage, calls := info.Usage()
+		common.LogInfo("背景更新快取完成",
+			zap.String("request_id", requestID),
+			zap.String("task", string(task)),
+			zap.Duration("duration", time.Since(start)),
+			zap.Int("ai_calls", calls),
+			zap.Int("total_tokens", usage.TotalTokens),
+			zap.Float64("cost", usage.Cost),
+		)
+	}()
+}
@@ -2,16 +2,26 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"recipe-generator/internal/core/ai/cache"
+	"recipe-generator/internal/core/ai/fallback"
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/core/ai/queue"
+	"recipe-generator/internal/core/ai/retry"
+	"recipe-generator/internal/core/ai/tools"
+	"recipe-generator/internal/core/ai/usage"
 	"recipe-generator/internal/core/image"
-	openrouter "recipe-generator/internal/core/service"
 	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
+
+	"go.uber.org/zap"
 )
 
 // Response AI 回應結構
@@ -20,87 +30,438 @@ import (
 // 若有多欄位可自訂 struct
 
 type Response struct {
-	Content string
+	Content  string
+	Model    string // 實際回應的模型，快取命中時為空
+	Template string // 產生 prompt 的模板與版本（name@vN）
+}
+
+// Request AI 請求
+type Request struct {
+	Task      provider.Task // 任務類型，決定模型路由
+	Prompt    string
+	ImageData string
+	Schema    *schema.Definition // 期望的回應結構，設定後會驗證回應並在不符時要求模型修正
+	Messages  []provider.Message // 置於本次 prompt 之前的對話紀錄（含 system 訊息）
+	NoCache   bool               // 不查詢也不寫入快取，prompt 僅去除首尾空白
+	Tools     []string           // 模型可呼叫的工具名稱
+	Template  string             // 產生 prompt 的模板與版本（name@vN），記錄於回應與日誌
+	Locale    string             // 輸出語系，納入快取鍵
+	CacheKey  string             // 結構化請求的標準化指紋，設定後取代 prompt 作為快取鍵
 }
 
 // Service AI 服務
 type Service struct {
 	config       *config.Config
-	openRouter   *openrouter.OpenRouterService
-	cacheManager *cache.CacheManager
+	provider     provider.Provider
+	cacheManager cache.Cache
+	imageIndex   *cache.ImageIndex // 圖片以感知哈希為緩存鍵時使用，否則為 nil
 	imageSvc     *image.Service
-	mu           sync.RWMutex
-	lastRequest  time.Time
+	pricing      usage.Pricing
+	tools        *tools.Registry
+	queue        *queue.Manager
+	flights      *flightGroup  // 合併進行中的相同請求，AI_COALESCE=false 時為 nil
+	refreshes    *refreshGroup // 進行中的背景快取更新，同一個鍵同時只更新一次
 }
 
 // NewService 創建 AI 服務
-func NewService(cfg *config.Config, cacheManager *cache.CacheManager) (*Service, error) {
-	// 創建 OpenRouter 服務
-	openRouter := openrouter.NewOpenRouterService(cfg)
+func NewService(cfg *config.Config, cacheManager cache.Cache) (*Service, error) {
+	// 解析模型單價，用於估算未回報費用的呼叫
+	pricing, err := usage.ParsePricing(cfg.AI.Pricing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI pricing: %w", err)
+	}
 
+	// 依設定建立 AI 提供者
+	p, err := NewProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI provider: %w", err)
+	}
+
+	svc := NewServiceWithProvider(cfg, cacheManager, p)
+	svc.pricing = pricing
+	return svc, nil
+}
+
+// NewServiceWithProvider 使用指定的 AI 提供者創建 AI 服務（可注入測試用提供者）
+func NewServiceWithProvider(cfg *config.Config, cacheManager cache.Cache, p provider.Provider) *Service {
 	// 創建圖片處理服務
 	imageSvc := image.NewService(cfg.Image.MaxSizeBytes)
 
-	return &Service{
+	svc := &Service{
 		config:       cfg,
-		openRouter:   openRouter,
+		provider:     p,
 		cacheManager: cacheManager,
 		imageSvc:     imageSvc,
-	}, nil
+		tools:        tools.NewDefaultRegistry(),
+		queue:        queue.NewManager(cfg),
+		refreshes:    newRefreshGroup(),
+	}
+	if cfg.AI.Coalesce {
+		svc.flights = newFlightGroup()
+	}
+	if cfg.Cache.ImageKey == cache.ImageKeyDHash {
+		svc.imageIndex = cache.NewImageIndex(cfg.Cache.ImageDistance, cfg.Cache.ImageIndexSize)
+	}
+	return svc
 }
 
-// ProcessRequest 統一對外方法
+// Tools 獲取工具註冊表，可註冊額外的伺服器端工具
+func (s *Service) Tools() *tools.Registry {
+	return s.tools
+}
+
+// ProcessRequest 以預設任務處理請求
 func (s *Service) ProcessRequest(ctx context.Context, prompt string, imageData string) (*Response, error) {
-	if err := s.checkRequestRate(); err != nil {
-		return nil, err
-	}
+	return s.Process(ctx, &Request{Prompt: prompt, ImageData: imageData})
+}
+
+// Process 統一對外方法
+func (s *Service) Process(ctx context.Context, req *Request) (*Response, error) {
+	return s.execute(ctx, req, nil)
+}
+
+// ProcessStream 以串流方式處理請求，每收到增量內容即呼叫 onDelta；
+// 快取命中時將完整內容作為單一增量輸出
+func (s *Service) ProcessStream(ctx context.Context, req *Request, onDelta provider.StreamHandler) (*Response, error) {
+	return s.execute(ctx, req, onDelta)
+}
+
+// execute 處理請求：正規化 prompt、處理圖片、查詢快取並呼叫 AI 提供者
+func (s *Service) execute(ctx context.Context, req *Request, onDelta provider.StreamHandler) (*Response, error) {
+	CallInfoFrom(ctx).recordTemplate(req.Template)
 
 	// 統一 prompt 格式，去除多餘空白、tab、換行，確保快取 key 一致
-	prompt = strings.TrimSpace(prompt)
-	prompt = strings.ReplaceAll(prompt, "\t", "")
-	prompt = strings.ReplaceAll(prompt, "\n", "")
-	prompt = strings.Join(strings.Fields(prompt), "")
+	prompt := strings.TrimSpace(req.Prompt)
+	if !req.NoCache {
+		prompt = strings.ReplaceAll(prompt, "\t", "")
+		prompt = strings.ReplaceAll(prompt, "\n", "")
+		prompt = strings.Join(strings.Fields(prompt), "")
+	}
+	useCache := !req.NoCache && s.config.Cache.Enabled && s.cacheManager != nil
+	// 不同語系的結果分開快取
+	cacheKey := prompt
+	if req.CacheKey != "" {
+		cacheKey = req.CacheKey
+	}
+	if req.Locale != "" {
+		cacheKey = "locale=" + req.Locale + ";" + cacheKey
+	}
 
 	var processedImageData string
-	if imageData != "" {
+	if req.ImageData != "" {
 		var err error
-		processedImageData, err = s.imageSvc.ProcessImage(imageData)
+		processedImageData, err = s.imageSvc.ProcessImage(req.ImageData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to process image: %w", err)
 		}
 	}
 
+	// 啟用感知哈希時，視覺上相近的圖片共用同一個緩存鍵
+	imageKey := processedImageData
+	if useCache && processedImageData != "" && s.imageIndex != nil {
+		if key, err := s.imageIndex.Key(processedImageData); err == nil {
+			imageKey = key
+		} else {
+			common.LogWarn("計算圖片感知哈希失敗，改用完整圖片內容作為快取鍵", zap.Error(err))
+		}
+	}
+
+	// 依任務類型分開快取的命名空間，供管理 API 分別統計與刪除
+	cacheCtx := cache.WithNamespace(ctx, string(req.Task))
+	key := flightKey(req.Task, cacheKey, imageKey)
+
+	// 呼叫 AI 提供者並寫入快取，供本次請求與背景更新共用
+	run := func(ctx context.Context, onDelta provider.StreamHandler) (*Response, error) {
+		response, err := s.fetch(ctx, req, prompt, processedImageData, onDelta)
+		if err == nil && useCache {
+			_ = s.cacheManager.Set(cache.WithNamespace(ctx, string(req.Task)), cacheKey, imageKey, response.Content)
+		}
+		return response, err
+	}
+
 	// 檢查緩存（用 cacheManager）
-	if s.config.Cache.Enabled && s.cacheManager != nil {
-		if val, err := s.cacheManager.Get(ctx, prompt, processedImageData); err == nil && val != "" {
-			return &Response{Content: val}, nil
+	if useCache {
+		// 不符合結構的舊快取視為未命中
+		if val, stale, err := s.cacheManager.Get(cacheCtx, cacheKey, imageKey); err == nil && val != "" &&
+			(req.Schema == nil || req.Schema.Validate(val) == nil) {
+			// 超過 CACHE_TTL 的值直接回傳，並在背景重新生成
+			status := CacheHit
+			if stale {
+				status = CacheStale
+				s.revalidate(ctx, req.Task, key, run)
+			}
+			CallInfoFrom(ctx).recordCache(status)
+			if onDelta != nil {
+				if err := onDelta(val); err != nil {
+					return nil, err
+				}
+			}
+			return &Response{Content: val, Template: req.Template}, nil
 		}
+		CallInfoFrom(ctx).recordCache(CacheMiss)
 	}
 
-	content, err := s.openRouter.GenerateResponse(ctx, prompt, processedImageData)
+	// 相同請求（同一快取鍵）正在進行時等待其結果，不重複呼叫上游；
+	// 快取由實際發出呼叫的一方寫入
+	if req.NoCache || s.flights == nil {
+		return run(ctx, onDelta)
+	}
+	return s.flights.do(ctx, key, onDelta, run)
+}
+
+// fetch 呼叫 AI 提供者（含工具呼叫與結構修正）
+func (s *Service) fetch(ctx context.Context, req *Request, prompt, imageData string, onDelta provider.StreamHandler) (*Response, error) {
+	// 依任務路由套用 token 上限與取樣參數
+	route := s.config.Routing.Route(string(req.Task))
+	preq := &provider.Request{
+		Task: req.Task,
+		Messages: append(append([]provider.Message(nil), req.Messages...), provider.Message{
+			Role:      "user",
+			Content:   prompt,
+			ImageData: imageData,
+		}),
+		MaxTokens:   s.config.OpenRouter.MaxTokens,
+		Temperature: route.Temperature,
+		TopP:        route.TopP,
+		Schema:      req.Schema,
+		Template:    req.Template,
+	}
+	if route.MaxTokens > 0 {
+		preq.MaxTokens = route.MaxTokens
+	}
+	if len(req.Tools) > 0 {
+		preq.Tools = s.tools.Definitions(req.Tools...)
+	}
+	resp, err := s.callWithTools(ctx, preq, onDelta)
 	if err != nil {
 		return nil, err
 	}
 
-	response := &Response{Content: content}
+	if req.Schema != nil {
+		if resp, err = s.repair(ctx, preq, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Response{Content: resp.Content, Model: resp.Model, Template: req.Template}, nil
+}
+
+// flightKey 合併請求與背景更新的鍵：任務類型、快取鍵與圖片鍵，圖片以哈希表示
+func flightKey(task provider.Task, cacheKey, imageKey string) string {
+	hash := sha256.Sum256([]byte(imageKey))
+	return string(task) + "\x00" + cacheKey + "\x00" + hex.EncodeToString(hash[:])
+}
+
+// call 經由請求隊列呼叫 AI 提供者，並將實際模型、使用量與費用記錄至請求的 CallInfo
+func (s *Service) call(ctx context.Context, preq *provider.Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	info := CallInfoFrom(ctx)
+	prompt := preq.Messages[len(preq.Messages)-1].Content
 
-	if s.config.Cache.Enabled && s.cacheManager != nil {
-		_ = s.cacheManager.Set(ctx, prompt, processedImageData, content)
+	start := time.Now()
+	var resp *provider.Response
+	err := s.queue.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.generate(ctx, preq, onDelta)
+		return err
+	})
+	if err != nil {
+		common.LogAICall(prompt, time.Since(start), err, info.RequestID(),
+			zap.String("task", string(preq.Task)),
+			zap.String("prompt_template", preq.Template),
+		)
+		return nil, err
 	}
 
-	return response, nil
+	resp.Usage.Cost = s.pricing.Cost(resp.Model, resp.Usage)
+	info.recordModel(resp.Model)
+	info.recordUsage(resp.Usage)
+
+	common.LogAICall(prompt, time.Since(start), nil, info.RequestID(),
+		zap.String("task", string(preq.Task)),
+		zap.String("prompt_template", preq.Template),
+		zap.String("model", resp.Model),
+		zap.Int("prompt_tokens", resp.Usage.PromptTokens),
+		zap.Int("completion_tokens", resp.Usage.CompletionTokens),
+		zap.Int("total_tokens", resp.Usage.TotalTokens),
+		zap.Float64("cost", resp.Usage.Cost),
+	)
+	return resp, nil
 }
 
-// checkRequestRate 檢查請求頻率
-func (s *Service) checkRequestRate() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// callWithTools 呼叫 AI 提供者並執行模型要求的工具，將結果回傳給模型，直到產生最終回應；
+// 超過 AI.MaxToolDepth 輪後以 tool_choice=none 要求模型直接回答
+func (s *Service) callWithTools(ctx context.Context, preq *provider.Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	info := CallInfoFrom(ctx)
+
+	resp, err := s.call(ctx, preq, onDelta)
+	for depth := 1; err == nil && len(resp.ToolCalls) > 0; depth++ {
+		if len(preq.Tools) == 0 || preq.ToolChoice == "none" {
+			break
+		}
 
-	now := time.Now()
-	if s.config.RateLimit.Enabled && now.Sub(s.lastRequest) < s.config.RateLimit.Window {
-		return errors.New("request rate limit exceeded")
+		preq.Messages = append(preq.Messages, provider.Message{
+			Role:      "assistant",
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+		for _, call := range resp.ToolCalls {
+			content, trace := s.tools.Call(ctx, call)
+			info.recordToolCall(trace)
+			common.LogDebug("AI 工具呼叫",
+				zap.String("request_id", info.RequestID()),
+				zap.String("tool", trace.Name),
+				zap.ByteString("arguments", trace.Arguments),
+				zap.ByteString("result", trace.Result),
+				zap.String("error", trace.Error),
+				zap.Float64("duration_ms", trace.Duration),
+			)
+			preq.Messages = append(preq.Messages, provider.Message{
+				Role:       "tool",
+				Content:    content,
+				ToolCallID: call.ID,
+			})
+		}
+
+		if depth >= s.config.AI.MaxToolDepth {
+			common.LogWarn("工具呼叫輪數已達上限，要求模型直接回答",
+				zap.String("request_id", info.RequestID()),
+				zap.Int("max_tool_depth", s.config.AI.MaxToolDepth),
+			)
+			preq.ToolChoice = "none"
+		}
+		resp, err = s.call(ctx, preq, onDelta)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// generate 呼叫 AI 提供者；onDelta 不為 nil 時使用串流
+func (s *Service) generate(ctx context.Context, req *provider.Request, onDelta provider.StreamHandler) (*provider.Response, error) {
+	if onDelta == nil {
+		return s.provider.Generate(ctx, req)
+	}
+
+	if sp, ok := s.provider.(provider.StreamProvider); ok {
+		return sp.GenerateStream(ctx, req, onDelta)
+	}
+
+	// 提供者不支援串流時，將完整內容作為單一增量輸出
+	resp, err := s.provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(resp.Content); err != nil {
+		return nil, err
 	}
+	return resp, nil
+}
+
+// repair 驗證回應是否符合結構，不符合時將驗證錯誤回饋給模型重新生成，
+// 最多重試 AI.SchemaRepairAttempts 次
+func (s *Service) repair(ctx context.Context, preq *provider.Request, resp *provider.Response) (*provider.Response, error) {
+	verr := preq.Schema.Validate(resp.Content)
+	for attempt := 1; verr != nil && attempt <= s.config.AI.SchemaRepairAttempts; attempt++ {
+		common.LogWarn("AI 回應未通過結構驗證，要求模型修正",
+			zap.String("schema", preq.Schema.Name),
+			zap.String("model", resp.Model),
+			zap.Int("attempt", attempt),
+			zap.Error(verr),
+		)
+		CallInfoFrom(ctx).recordRepair()
+
+		preq.Messages = append(preq.Messages,
+			provider.Message{Role: "assistant", Content: resp.Content},
+			provider.Message{Role: "user", Content: repairPrompt(verr)},
+		)
 
-	s.lastRequest = now
+		var err error
+		resp, err = s.call(ctx, preq, nil)
+		if err != nil {
+			return nil, err
+		}
+		verr = preq.Schema.Validate(resp.Content)
+	}
+
+	if verr != nil {
+		return nil, fmt.Errorf("AI response failed schema validation: %w", verr)
+	}
+	return resp, nil
+}
+
+// repairPrompt 組裝結構修正提示
+func repairPrompt(verr error) string {
+	var sb strings.Builder
+	sb.WriteString("你上一次的回應未通過 JSON 結構驗證，問題如下：\n")
+	var ve *schema.ValidationError
+	if errors.As(verr, &ve) {
+		for _, problem := range ve.Problems {
+			sb.WriteString("- " + problem + "\n")
+		}
+	} else {
+		sb.WriteString("- " + verr.Error() + "\n")
+	}
+	sb.WriteString("請修正以上問題，保留原本的內容，只回傳一個完整且符合格式的 JSON，不要包含任何說明文字或 markdown。")
+	return sb.String()
+}
+
+// Model 獲取預設模型名稱
+func (s *Service) Model() string {
+	return s.provider.GetModel()
+}
+
+// BreakerStatus 獲取各模型的斷路器狀態
+func (s *Service) BreakerStatus() []fallback.BreakerStatus {
+	if chain, ok := s.provider.(*fallback.Chain); ok {
+		return chain.BreakerStatus()
+	}
 	return nil
 }
+
+// RetryStats 獲取上游重試統計，提供者不支援重試時返回 nil
+func (s *Service) RetryStats() *retry.Stats {
+	p := s.provider
+	for p != nil {
+		if r, ok := p.(interface{ RetryStats() retry.Stats }); ok {
+			stats := r.RetryStats()
+			return &stats
+		}
+		w, ok := p.(provider.Wrapper)
+		if !ok {
+			break
+		}
+		p = w.Unwrap()
+	}
+	return nil
+}
+
+// ErrProbeUnsupported 提供者無法探測上游（如回放模式）
+var ErrProbeUnsupported = errors.New("provider does not support probing")
+
+// Probe 逐層尋找支援探測的提供者，檢查上游連線與憑證
+func (s *Service) Probe(ctx context.Context) error {
+	p := s.provider
+	for p != nil {
+		if prober, ok := p.(provider.Prober); ok {
+			return prober.Probe(ctx)
+		}
+		w, ok := p.(provider.Wrapper)
+		if !ok {
+			break
+		}
+		p = w.Unwrap()
+	}
+	return ErrProbeUnsupported
+}
+
+// QueueStatus 獲取請求隊列狀態
+func (s *Service) QueueStatus() *queue.Status {
+	return s.queue.GetQueueStatus()
+}
+
+// Close 等待隊列中的請求處理完畢後關閉 AI 提供者
+func (s *Service) Close() error {
+	s.queue.Close()
+	return s.provider.Close()
+}
@@ -0,0 +1,302 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
+
+	"github.com/google/uuid"
+)
+
+// 內建工具名稱
+const (
+	ConvertUnitsName    = "convert_units"
+	LookupNutritionName = "lookup_nutrition"
+	GetPantryName       = "get_pantry"
+	CreateTimerName     = "create_timer"
+)
+
+// maxTimerMinutes 計時器最長時間
+const maxTimerMinutes = 24 * 60
+
+// NewDefaultRegistry 創建包含所有內建工具的註冊表
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, tool := range []Tool{convertUnitsTool, lookupNutritionTool, getPantryTool, createTimerTool} {
+		// 內建工具名稱固定且不重複
+		_ = r.Register(tool)
+	}
+	return r
+}
+
+// --- 單位換算 ---
+
+// unit 單位所屬的量綱與換算為基準單位（公克 / 毫升）的倍率
+type unit struct {
+	dimension string
+	factor    float64
+}
+
+var units = map[string]unit{
+	"g": {"mass", 1}, "克": {"mass", 1}, "公克": {"mass", 1},
+	"kg": {"mass", 1000}, "公斤": {"mass", 1000},
+	"mg": {"mass", 0.001},
+	"oz": {"mass", 28.3495}, "lb": {"mass", 453.592},
+	"斤": {"mass", 600}, "兩": {"mass", 37.5},
+	"ml": {"volume", 1}, "毫升": {"volume", 1},
+	"l": {"volume", 1000}, "公升": {"volume", 1000},
+	"tsp": {"volume", 5}, "小匙": {"volume", 5}, "茶匙": {"volume", 5},
+	"tbsp": {"volume", 15}, "大匙": {"volume", 15}, "湯匙": {"volume", 15},
+	"cup": {"volume", 240}, "杯": {"volume", 240},
+	"fl_oz": {"volume", 29.5735},
+}
+
+var convertUnitsTool = Tool{
+	Definition: provider.ToolDefinition{
+		Name:        ConvertUnitsName,
+		Description: "換算烹飪單位。支援重量（g、kg、mg、oz、lb、斤、兩）、體積（ml、l、tsp/小匙、tbsp/大匙、cup/杯、fl_oz）與溫度（c、f）。",
+		Parameters: schema.Object(
+			schema.Prop("value", schema.Number("數值")),
+			schema.Prop("from", schema.String("原單位")),
+			schema.Prop("to", schema.String("目標單位")),
+		),
+	},
+	Handler: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		var in struct {
+			Value float64 `json:"value"`
+			From  string  `json:"from"`
+			To    string  `json:"to"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		result, err := ConvertUnits(in.Value, in.From, in.To)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"value":  in.Value,
+			"from":   in.From,
+			"to":     in.To,
+			"result": result,
+		}, nil
+	},
+}
+
+// ConvertUnits 換算烹飪單位，結果四捨五入至小數點後兩位
+func ConvertUnits(value float64, from, to string) (float64, error) {
+	from, to = normalizeUnit(from), normalizeUnit(to)
+
+	if isTemperature(from) || isTemperature(to) {
+		if !isTemperature(from) || !isTemperature(to) {
+			return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+		}
+		switch {
+		case from == to:
+			return round2(value), nil
+		case from == "c":
+			return round2(value*9/5 + 32), nil
+		default:
+			return round2((value - 32) * 5 / 9), nil
+		}
+	}
+
+	fu, ok := units[from]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit: %s", from)
+	}
+	tu, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit: %s", to)
+	}
+	if fu.dimension != tu.dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, fu.dimension, to, tu.dimension)
+	}
+	return round2(value * fu.factor / tu.factor), nil
+}
+
+// normalizeUnit 統一單位寫法
+func normalizeUnit(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	switch u {
+	case "°c", "℃", "攝氏", "celsius":
+		return "c"
+	case "°f", "℉", "華氏", "fahrenheit":
+		return "f"
+	case "cups":
+		return "cup"
+	case "teaspoon", "teaspoons":
+		return "tsp"
+	case "tablespoon", "tablespoons":
+		return "tbsp"
+	}
+	return u
+}
+
+func isTemperature(u string) bool {
+	return u == "c" || u == "f"
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// --- 營養查詢 ---
+
+// Nutrition 每 100 公克的營養成分
+type Nutrition struct {
+	Calories float64 `json:"calories_kcal"`
+	Protein  float64 `json:"protein_g"`
+	Fat      float64 `json:"fat_g"`
+	Carbs    float64 `json:"carbs_g"`
+}
+
+// nutritionTable 常見食材營養成分（每 100 公克），鍵為所有可接受的名稱
+var nutritionTable = func() map[string]Nutrition {
+	entries := []struct {
+		names []string
+		n     Nutrition
+	}{
+		{[]string{"雞蛋", "蛋", "egg"}, Nutrition{143, 12.6, 9.5, 0.7}},
+		{[]string{"番茄", "蕃茄", "tomato"}, Nutrition{18, 0.9, 0.2, 3.9}},
+		{[]string{"白飯", "米飯", "rice"}, Nutrition{130, 2.7, 0.3, 28.2}},
+		{[]string{"雞胸肉", "chicken breast"}, Nutrition{165, 31, 3.6, 0}},
+		{[]string{"雞腿", "chicken thigh"}, Nutrition{209, 26, 10.9, 0}},
+		{[]string{"豬肉", "pork"}, Nutrition{242, 27, 14, 0}},
+		{[]string{"牛肉", "beef"}, Nutrition{250, 26, 15, 0}},
+		{[]string{"鮭魚", "salmon"}, Nutrition{208, 20, 13, 0}},
+		{[]string{"豆腐", "tofu"}, Nutrition{76, 8, 4.8, 1.9}},
+		{[]string{"洋蔥", "onion"}, Nutrition{40, 1.1, 0.1, 9.3}},
+		{[]string{"馬鈴薯", "potato"}, Nutrition{77, 2, 0.1, 17}},
+		{[]string{"高麗菜", "cabbage"}, Nutrition{25, 1.3, 0.1, 5.8}},
+		{[]string{"紅蘿蔔", "胡蘿蔔", "carrot"}, Nutrition{41, 0.9, 0.2, 9.6}},
+		{[]string{"青花菜", "花椰菜", "broccoli"}, Nutrition{34, 2.8, 0.4, 6.6}},
+		{[]string{"大蒜", "蒜", "garlic"}, Nutrition{149, 6.4, 0.5, 33}},
+		{[]string{"牛奶", "milk"}, Nutrition{42, 3.4, 1, 5}},
+		{[]string{"麵條", "noodles"}, Nutrition{138, 4.5, 2.1, 25}},
+		{[]string{"奶油", "butter"}, Nutrition{717, 0.9, 81, 0.1}},
+		{[]string{"橄欖油", "olive oil"}, Nutrition{884, 0, 100, 0}},
+		{[]string{"砂糖", "糖", "sugar"}, Nutrition{387, 0, 0, 100}},
+	}
+	table := make(map[string]Nutrition)
+	for _, e := range entries {
+		for _, name := range e.names {
+			table[name] = e.n
+		}
+	}
+	return table
+}()
+
+var lookupNutritionTool = Tool{
+	Definition: provider.ToolDefinition{
+		Name:        LookupNutritionName,
+		Description: "查詢常見食材的營養成分（熱量、蛋白質、脂肪、碳水化合物），依指定重量計算。",
+		Parameters: schema.Object(
+			schema.Prop("ingredient", schema.String("食材名稱（中文或英文）")),
+			schema.Prop("grams", schema.Number("重量（公克）")),
+		),
+	},
+	Handler: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		var in struct {
+			Ingredient string  `json:"ingredient"`
+			Grams      float64 `json:"grams"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		if in.Grams <= 0 {
+			in.Grams = 100
+		}
+		n, ok := nutritionTable[strings.ToLower(strings.TrimSpace(in.Ingredient))]
+		if !ok {
+			return nil, fmt.Errorf("no nutrition data for %s", in.Ingredient)
+		}
+		ratio := in.Grams / 100
+		return map[string]interface{}{
+			"ingredient": in.Ingredient,
+			"grams":      in.Grams,
+			"nutrition": Nutrition{
+				Calories: round2(n.Calories * ratio),
+				Protein:  round2(n.Protein * ratio),
+				Fat:      round2(n.Fat * ratio),
+				Carbs:    round2(n.Carbs * ratio),
+			},
+		}, nil
+	},
+}
+
+// --- 使用者食材庫 ---
+
+// pantryKey 食材庫的 context key
+type pantryKey struct{}
+
+// ErrNoPantry 本次請求沒有可用的食材庫
+var ErrNoPantry = errors.New("no pantry available for this request")
+
+// WithPantry 在 context 中附加使用者手邊的食材與設備，供 get_pantry 工具查詢
+func WithPantry(ctx context.Context, pantry *common.IngredientRecognitionResult) context.Context {
+	return context.WithValue(ctx, pantryKey{}, pantry)
+}
+
+var getPantryTool = Tool{
+	Definition: provider.ToolDefinition{
+		Name:        GetPantryName,
+		Description: "取得使用者目前手邊的食材與廚房設備。",
+		Parameters:  schema.Object(),
+	},
+	Handler: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		pantry, _ := ctx.Value(pantryKey{}).(*common.IngredientRecognitionResult)
+		if pantry == nil {
+			return nil, ErrNoPantry
+		}
+		return pantry, nil
+	},
+}
+
+// --- 計時器 ---
+
+// Timer 模型建立的烹飪計時器，由客戶端負責實際倒數
+type Timer struct {
+	ID              string    `json:"id"`
+	Label           string    `json:"label"`
+	DurationSeconds int       `json:"duration_seconds"`
+	EndsAt          time.Time `json:"ends_at"`
+}
+
+var createTimerTool = Tool{
+	Definition: provider.ToolDefinition{
+		Name:        CreateTimerName,
+		Description: "為烹飪步驟建立計時器，例如燉煮 20 分鐘。",
+		Parameters: schema.Object(
+			schema.Prop("label", schema.String("計時器名稱，例如「燉牛肉」")),
+			schema.Prop("minutes", schema.Number("時間（分鐘）")),
+		),
+	},
+	Handler: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+		var in struct {
+			Label   string  `json:"label"`
+			Minutes float64 `json:"minutes"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		if in.Minutes <= 0 || in.Minutes > maxTimerMinutes {
+			return nil, fmt.Errorf("minutes must be between 0 and %d", maxTimerMinutes)
+		}
+		duration := time.Duration(in.Minutes * float64(time.Minute)).Round(time.Second)
+		return Timer{
+			ID:              uuid.New().String(),
+			Label:           in.Label,
+			DurationSeconds: int(duration / time.Second),
+			EndsAt:          time.Now().Add(duration),
+		}, nil
+	},
+}
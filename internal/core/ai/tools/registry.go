@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+)
+
+// ErrUnknownTool 模型要求呼叫未註冊的工具
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Handler 工具實作，args 為模型提供的 JSON 參數，返回值會序列化為 JSON 回傳給模型
+type Handler func(ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// Tool 可供模型呼叫的伺服器端函式
+type Tool struct {
+	Definition provider.ToolDefinition
+	Handler    Handler
+}
+
+// Trace 單次工具呼叫紀錄，用於除錯輸出
+type Trace struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Duration  float64         `json:"duration_ms"`
+}
+
+// Registry 工具註冊表
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+// NewRegistry 創建空的工具註冊表
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]Tool)}
+}
+
+// Register 註冊工具，名稱重複時返回錯誤
+func (r *Registry) Register(tool Tool) error {
+	name := tool.Definition.Name
+	if name == "" || tool.Handler == nil {
+		return fmt.Errorf("tool name and handler are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; exists {
+		return fmt.Errorf("tool %q already registered", name)
+	}
+	r.tools[name] = tool
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Definitions 獲取指定工具的定義（依註冊順序），未指定時返回全部
+func (r *Registry) Definitions(names ...string) []provider.ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	defs := make([]provider.ToolDefinition, 0, len(r.order))
+	for _, name := range r.order {
+		if len(names) == 0 || wanted[name] {
+			defs = append(defs, r.tools[name].Definition)
+		}
+	}
+	return defs
+}
+
+// Call 執行模型要求的工具呼叫，返回回傳給模型的 JSON 內容與呼叫紀錄。
+// 工具執行失敗時以 {"error": "..."} 回傳給模型，讓模型自行調整，不中斷生成
+func (r *Registry) Call(ctx context.Context, call provider.ToolCall) (string, Trace) {
+	args := json.RawMessage(call.Arguments)
+	var err error
+	switch {
+	case len(args) == 0:
+		args = json.RawMessage("{}")
+	case !json.Valid(args):
+		err = fmt.Errorf("invalid arguments: not valid JSON")
+		args, _ = json.Marshal(call.Arguments)
+	}
+	trace := Trace{Name: call.Name, Arguments: args}
+
+	r.mu.RLock()
+	tool, ok := r.tools[call.Name]
+	r.mu.RUnlock()
+
+	start := time.Now()
+	var result interface{}
+	switch {
+	case !ok:
+		err = fmt.Errorf("%w: %s", ErrUnknownTool, call.Name)
+	case err == nil:
+		result, err = tool.Handler(ctx, args)
+	}
+	trace.Duration = float64(time.Since(start).Microseconds()) / 1000
+
+	if err != nil {
+		trace.Error = err.Error()
+		result = map[string]string{"error": err.Error()}
+	}
+	content, merr := json.Marshal(result)
+	if merr != nil {
+		trace.Error = merr.Error()
+		content = []byte(`{"error":"failed to encode tool result"}`)
+	}
+	if trace.Error == "" {
+		trace.Result = content
+	}
+	return string(content), trace
+}
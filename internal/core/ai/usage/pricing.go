@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"recipe-generator/internal/core/ai/provider"
+)
+
+// Price 模型單價（美元 / 每百萬 tokens）
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Pricing 各模型單價表，鍵為模型名稱或以 * 結尾的前綴
+type Pricing map[string]Price
+
+// ParsePricing 解析單價設定，格式為 "model=prompt:completion,..."，
+// 例如 "google/gemini-2.0-flash-001=0.1:0.4,openai/*=2.5:10"
+func ParsePricing(s string) (Pricing, error) {
+	pricing := make(Pricing)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, prices, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pricing entry %q: expected model=prompt:completion", entry)
+		}
+		promptStr, completionStr, ok := strings.Cut(prices, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid pricing entry %q: expected model=prompt:completion", entry)
+		}
+		prompt, err := strconv.ParseFloat(strings.TrimSpace(promptStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt price in %q: %w", entry, err)
+		}
+		completion, err := strconv.ParseFloat(strings.TrimSpace(completionStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completion price in %q: %w", entry, err)
+		}
+		pricing[strings.TrimSpace(model)] = Price{Prompt: prompt, Completion: completion}
+	}
+	return pricing, nil
+}
+
+// Lookup 查詢模型單價，完整名稱優先，其次為最長的前綴
+func (p Pricing) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+
+	var (
+		best    Price
+		bestLen = -1
+	)
+	for key, price := range p {
+		prefix, ok := strings.CutSuffix(key, "*")
+		if ok && strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = price, len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// Cost 計算單次呼叫的費用：提供者回報的費用優先，否則依單價表估算
+func (p Pricing) Cost(model string, u provider.Usage) float64 {
+	if u.Cost > 0 {
+		return u.Cost
+	}
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(u.PromptTokens)*price.Prompt + float64(u.CompletionTokens)*price.Completion) / 1e6
+}
@@ -0,0 +1,142 @@
+package usage
+
+import (
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+)
+
+// maxClients 最多分別統計的客戶端數量，超過的客戶端合併為 OtherClient
+const maxClients = 1000
+
+// OtherClient 超過統計上限的客戶端合併名稱
+const OtherClient = "_other"
+
+// Stats 使用量統計
+type Stats struct {
+	Requests         int64   `json:"requests"`
+	AICalls          int64   `json:"ai_calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// add 累加一次請求的使用量
+func (s *Stats) add(calls int, u provider.Usage) {
+	s.Requests++
+	s.AICalls += int64(calls)
+	s.PromptTokens += int64(u.PromptTokens)
+	s.CompletionTokens += int64(u.CompletionTokens)
+	s.TotalTokens += int64(u.TotalTokens)
+	s.Cost += u.Cost
+}
+
+// ClientReport 單一客戶端的使用量，含各端點明細
+type ClientReport struct {
+	Stats
+	Endpoints map[string]Stats `json:"endpoints"`
+}
+
+// Report 使用量報表
+type Report struct {
+	Since     time.Time                `json:"since"`
+	Total     Stats                    `json:"total"`
+	Clients   map[string]*ClientReport `json:"clients"`
+	Endpoints map[string]Stats         `json:"endpoints"`
+}
+
+// Tracker 依客戶端與端點彙總 AI 使用量
+type Tracker struct {
+	mu        sync.Mutex
+	since     time.Time
+	total     Stats
+	clients   map[string]*clientStats
+	endpoints map[string]*Stats
+}
+
+// clientStats 客戶端統計
+type clientStats struct {
+	Stats
+	endpoints map[string]*Stats
+}
+
+// NewTracker 創建使用量統計器
+func NewTracker() *Tracker {
+	return &Tracker{
+		since:     time.Now(),
+		clients:   make(map[string]*clientStats),
+		endpoints: make(map[string]*Stats),
+	}
+}
+
+// Record 記錄一次請求的使用量
+func (t *Tracker) Record(client, endpoint string, calls int, u provider.Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total.add(calls, u)
+
+	ep, ok := t.endpoints[endpoint]
+	if !ok {
+		ep = &Stats{}
+		t.endpoints[endpoint] = ep
+	}
+	ep.add(calls, u)
+
+	cs, ok := t.clients[client]
+	if !ok {
+		if len(t.clients) >= maxClients {
+			client = OtherClient
+			cs = t.clients[client]
+		}
+		if cs == nil {
+			cs = &clientStats{endpoints: make(map[string]*Stats)}
+			t.clients[client] = cs
+		}
+	}
+	cs.add(calls, u)
+
+	cep, ok := cs.endpoints[endpoint]
+	if !ok {
+		cep = &Stats{}
+		cs.endpoints[endpoint] = cep
+	}
+	cep.add(calls, u)
+}
+
+// Report 獲取目前的使用量報表
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		Since:     t.since,
+		Total:     t.total,
+		Clients:   make(map[string]*ClientReport, len(t.clients)),
+		Endpoints: make(map[string]Stats, len(t.endpoints)),
+	}
+	for name, ep := range t.endpoints {
+		report.Endpoints[name] = *ep
+	}
+	for name, cs := range t.clients {
+		cr := &ClientReport{Stats: cs.Stats, Endpoints: make(map[string]Stats, len(cs.endpoints))}
+		for ep, stats := range cs.endpoints {
+			cr.Endpoints[ep] = *stats
+		}
+		report.Clients[name] = cr
+	}
+	return report
+}
+
+// Reset 清除統計並重新起算
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.since = time.Now()
+	t.total = Stats{}
+	t.clients = make(map[string]*clientStats)
+	t.endpoints = make(map[string]*Stats)
+}
@@ -0,0 +1,285 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/ai/tools"
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 依據類型對應的系統提示描述
+var contextLabels = map[string]string{
+	ContextRecipe:                "使用者正在製作的食譜",
+	ContextFoodRecognition:       "使用者拍攝的料理辨識結果",
+	ContextIngredientRecognition: "使用者手邊食材與設備的辨識結果",
+}
+
+// chatTools 對話中模型可呼叫的工具
+var chatTools = []string{
+	tools.ConvertUnitsName,
+	tools.LookupNutritionName,
+	tools.GetPantryName,
+	tools.CreateTimerName,
+}
+
+// Reply 單次提問的回覆
+type Reply struct {
+	SessionID  string        `json:"session_id"`
+	Reply      string        `json:"reply"`
+	Turns      int           `json:"turns"`
+	Summarized bool          `json:"summarized"` // 本次是否將較早的訊息整理為摘要
+	Timers     []tools.Timer `json:"timers,omitempty"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	ToolTrace  []tools.Trace `json:"tool_trace,omitempty"` // 僅於除錯模式輸出
+}
+
+// Service 烹飪助理對話服務
+type Service struct {
+	aiService *service.Service
+	store     *Store
+	config    config.ChatConfig
+}
+
+// NewService 創建對話服務
+func NewService(aiService *service.Service, cfg config.ChatConfig) *Service {
+	return &Service{
+		aiService: aiService,
+		store:     NewStore(cfg.SessionTTL, cfg.MaxSessions),
+		config:    cfg,
+	}
+}
+
+// CreateSession 以食譜或辨識結果為依據建立新對話
+func (s *Service) CreateSession(grounding Grounding) (*SessionView, error) {
+	kind, context, err := grounding.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:          uuid.New().String(),
+		ContextType: kind,
+		Context:     context,
+		Pantry:      grounding.IngredientRecognition,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	session.ExpiresAt = s.store.Add(session)
+
+	common.LogInfo("已建立烹飪助理對話",
+		zap.String("session_id", session.ID),
+		zap.String("context_type", kind),
+	)
+	return session.view(), nil
+}
+
+// Get 獲取對話快照
+func (s *Service) Get(id string) (*SessionView, error) {
+	session, expiresAt, ok := s.store.Get(id)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.ExpiresAt = expiresAt
+	return session.view(), nil
+}
+
+// Delete 刪除對話
+func (s *Service) Delete(id string) error {
+	if !s.store.Delete(id) {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Close 停止過期清理
+func (s *Service) Close() {
+	s.store.Close()
+}
+
+// Ask 在對話中提問；對話紀錄超過上限時會將較早的訊息整理為摘要
+func (s *Service) Ask(ctx context.Context, id, question string) (*Reply, error) {
+	session, expiresAt, ok := s.store.Get(id)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.Pantry != nil {
+		ctx = tools.WithPantry(ctx, session.Pantry)
+	}
+
+	question = strings.TrimSpace(question)
+	resp, err := s.aiService.Process(ctx, &service.Request{
+		Task:     provider.TaskChat,
+		Prompt:   question,
+		Messages: append([]provider.Message{{Role: "system", Content: s.systemPrompt(session)}}, session.Messages...),
+		NoCache:  true,
+		Tools:    chatTools,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AI service error: %w", err)
+	}
+	answer := strings.TrimSpace(resp.Content)
+	if answer == "" {
+		return nil, fmt.Errorf("empty AI response")
+	}
+
+	session.Messages = append(session.Messages,
+		provider.Message{Role: "user", Content: question},
+		provider.Message{Role: "assistant", Content: answer},
+	)
+	session.Turns++
+	session.UpdatedAt = time.Now()
+	session.ExpiresAt = expiresAt
+
+	return &Reply{
+		SessionID:  session.ID,
+		Reply:      answer,
+		Turns:      session.Turns,
+		Summarized: s.compact(ctx, session),
+		Timers:     createdTimers(service.CallInfoFrom(ctx).ToolCalls()),
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// createdTimers 從工具呼叫紀錄取出本次建立的計時器
+func createdTimers(traces []tools.Trace) []tools.Timer {
+	var timers []tools.Timer
+	for _, trace := range traces {
+		if trace.Name != tools.CreateTimerName || trace.Error != "" {
+			continue
+		}
+		var timer tools.Timer
+		if err := json.Unmarshal(trace.Result, &timer); err == nil {
+			timers = append(timers, timer)
+		}
+	}
+	return timers
+}
+
+// systemPrompt 組裝包含對話依據與摘要的系統提示
+func (s *Service) systemPrompt(session *Session) string {
+	var b strings.Builder
+	b.WriteString("你是一位親切的烹飪助理，請用繁體中文簡潔回答使用者的烹飪問題。")
+	b.WriteString("回答須以下方資料為依據；資料未提及的內容請明確說明是一般建議。")
+	b.WriteString("需要換算單位、查詢營養成分、查看使用者手邊食材或設定計時器時，請使用提供的工具。\n\n")
+	fmt.Fprintf(&b, "%s（JSON）：\n%s\n", contextLabels[session.ContextType], session.Context)
+	if session.Summary != "" {
+		fmt.Fprintf(&b, "\n先前對話摘要：\n%s\n", session.Summary)
+	}
+	return b.String()
+}
+
+// compact 對話紀錄超過訊息數或 token 上限時，將較早的訊息整理為摘要，
+// 只保留最近 KeepRecent 則原文；摘要失敗時直接捨棄較早的訊息。
+// 呼叫端需持有 session.mu，返回是否產生了新摘要
+func (s *Service) compact(ctx context.Context, session *Session) bool {
+	if !s.overBudget(session.Messages) {
+		return false
+	}
+
+	split := len(session.Messages) - s.config.KeepRecent
+	// 保持 user/assistant 成對，避免保留的紀錄以 assistant 開頭
+	if split%2 != 0 {
+		split++
+	}
+	if split <= 0 {
+		return false
+	}
+	older, recent := session.Messages[:split], session.Messages[split:]
+
+	summary, err := s.summarize(ctx, session.Summary, older)
+	if err != nil {
+		common.LogWarn("對話摘要失敗，捨棄較早的訊息",
+			zap.Error(err),
+			zap.String("session_id", session.ID),
+			zap.Int("dropped", len(older)),
+		)
+		session.Messages = append([]provider.Message(nil), recent...)
+		return false
+	}
+
+	session.Summary = summary
+	session.Messages = append([]provider.Message(nil), recent...)
+	common.LogInfo("已摘要較早的對話紀錄",
+		zap.String("session_id", session.ID),
+		zap.Int("summarized", len(older)),
+		zap.Int("kept", len(recent)),
+	)
+	return true
+}
+
+// overBudget 檢查對話紀錄是否超過訊息數或估計 token 上限
+func (s *Service) overBudget(messages []provider.Message) bool {
+	if s.config.MaxHistory > 0 && len(messages) > s.config.MaxHistory {
+		return true
+	}
+	if s.config.TokenBudget <= 0 {
+		return false
+	}
+	tokens := 0
+	for _, m := range messages {
+		tokens += estimateTokens(m.Content)
+	}
+	return tokens > s.config.TokenBudget
+}
+
+// summarize 請模型將既有摘要與較早的訊息整理為新摘要
+func (s *Service) summarize(ctx context.Context, previous string, messages []provider.Message) (string, error) {
+	var b strings.Builder
+	b.WriteString("請將以下烹飪助理的對話整理為 200 字以內的繁體中文摘要，")
+	b.WriteString("保留使用者的偏好、限制、已完成的步驟與尚未解決的問題，只輸出摘要內容。\n\n")
+	if previous != "" {
+		fmt.Fprintf(&b, "既有摘要：\n%s\n\n", previous)
+	}
+	b.WriteString("對話：\n")
+	for _, m := range messages {
+		role := "使用者"
+		if m.Role == "assistant" {
+			role = "助理"
+		}
+		fmt.Fprintf(&b, "%s：%s\n", role, m.Content)
+	}
+
+	resp, err := s.aiService.Process(ctx, &service.Request{
+		Task:    provider.TaskChat,
+		Prompt:  b.String(),
+		NoCache: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return summary, nil
+}
+
+// estimateTokens 粗略估計 token 數：ASCII 約 4 字元一個 token，其餘字元各算一個
+func estimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < 128 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return other + (ascii+3)/4
+}
@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"recipe-generator/internal/core/ai/provider"
+	"recipe-generator/internal/pkg/common"
+)
+
+var (
+	// ErrSessionNotFound 對話不存在或已過期
+	ErrSessionNotFound = errors.New("chat session not found")
+	// ErrNoGrounding 建立對話時未附加食譜或辨識結果
+	ErrNoGrounding = errors.New("chat session requires a recipe or recognition result")
+)
+
+// 對話依據的類型
+const (
+	ContextRecipe                = "recipe"
+	ContextFoodRecognition       = "food_recognition"
+	ContextIngredientRecognition = "ingredient_recognition"
+)
+
+// Grounding 建立對話時附加的依據，三者擇一
+type Grounding struct {
+	Recipe                *common.Recipe                      `json:"recipe,omitempty"`
+	FoodRecognition       *common.FoodRecognitionResult       `json:"food_recognition,omitempty"`
+	IngredientRecognition *common.IngredientRecognitionResult `json:"ingredient_recognition,omitempty"`
+}
+
+// resolve 返回依據類型與其 JSON 內容
+func (g Grounding) resolve() (string, string, error) {
+	var (
+		kind  string
+		value interface{}
+	)
+	switch {
+	case g.Recipe != nil:
+		kind, value = ContextRecipe, g.Recipe
+	case g.FoodRecognition != nil:
+		kind, value = ContextFoodRecognition, g.FoodRecognition
+	case g.IngredientRecognition != nil:
+		kind, value = ContextIngredientRecognition, g.IngredientRecognition
+	default:
+		return "", "", ErrNoGrounding
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", "", err
+	}
+	return kind, string(data), nil
+}
+
+// Session 單一烹飪助理對話
+type Session struct {
+	mu sync.Mutex // 序列化同一對話的提問
+
+	ID          string
+	ContextType string
+	Context     string                              // 依據內容（JSON）
+	Pantry      *common.IngredientRecognitionResult // 以食材辨識結果建立時，供 get_pantry 工具查詢
+	Summary     string                              // 較早訊息的摘要
+	Messages    []provider.Message
+	Turns       int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// SessionView 對話的唯讀快照
+type SessionView struct {
+	ID          string             `json:"session_id"`
+	ContextType string             `json:"context_type"`
+	Context     json.RawMessage    `json:"context"`
+	Summary     string             `json:"summary,omitempty"`
+	Messages    []provider.Message `json:"messages"`
+	Turns       int                `json:"turns"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	ExpiresAt   time.Time          `json:"expires_at"`
+}
+
+// view 建立快照，呼叫端需持有 s.mu
+func (s *Session) view() *SessionView {
+	return &SessionView{
+		ID:          s.ID,
+		ContextType: s.ContextType,
+		Context:     json.RawMessage(s.Context),
+		Summary:     s.Summary,
+		Messages:    append([]provider.Message{}, s.Messages...),
+		Turns:       s.Turns,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		ExpiresAt:   s.ExpiresAt,
+	}
+}
@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"sync"
+	"time"
+
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// Store 記憶體中的對話儲存，閒置超過 TTL 的對話會被移除
+type Store struct {
+	mu          sync.Mutex
+	sessions    map[string]*storeEntry
+	ttl         time.Duration
+	maxSessions int
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// storeEntry 對話與其過期時間；過期時間由 Store 管理，避免等待進行中的提問
+type storeEntry struct {
+	session   *Session
+	expiresAt time.Time
+}
+
+// NewStore 創建對話儲存並啟動過期清理
+func NewStore(ttl time.Duration, maxSessions int) *Store {
+	s := &Store{
+		sessions:    make(map[string]*storeEntry),
+		ttl:         ttl,
+		maxSessions: maxSessions,
+		done:        make(chan struct{}),
+	}
+	go s.startCleanup()
+	return s
+}
+
+// Add 加入新對話並返回過期時間；達到上限時移除最快過期的對話
+func (s *Store) Add(session *Session) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.sessions) >= s.maxSessions {
+		s.evictLocked()
+	}
+	expiresAt := time.Now().Add(s.ttl)
+	s.sessions[session.ID] = &storeEntry{session: session, expiresAt: expiresAt}
+	return expiresAt
+}
+
+// Get 獲取未過期的對話並延長其過期時間
+func (s *Store) Get(id string) (*Session, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.sessions[id]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	now := time.Now()
+	if now.After(entry.expiresAt) {
+		delete(s.sessions, id)
+		return nil, time.Time{}, false
+	}
+	entry.expiresAt = now.Add(s.ttl)
+	return entry.session, entry.expiresAt, true
+}
+
+// Delete 刪除對話，返回是否存在
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	return ok
+}
+
+// Len 獲取目前的對話數
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// Close 停止過期清理
+func (s *Store) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// evictLocked 移除最快過期的對話，呼叫端需持有 s.mu
+func (s *Store) evictLocked() {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+	for id, entry := range s.sessions {
+		if oldestID == "" || entry.expiresAt.Before(oldest) {
+			oldestID, oldest = id, entry.expiresAt
+		}
+	}
+	if oldestID != "" {
+		delete(s.sessions, oldestID)
+		common.LogWarn("對話數已達上限，移除最舊的對話",
+			zap.String("session_id", oldestID),
+			zap.Int("max_sessions", s.maxSessions),
+		)
+	}
+}
+
+// startCleanup 定期移除過期對話
+func (s *Store) startCleanup() {
+	interval := s.ttl / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.cleanup()
+		}
+	}
+}
+
+// cleanup 移除所有過期對話
+func (s *Store) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for id, entry := range s.sessions {
+		if now.After(entry.expiresAt) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	if removed > 0 {
+		common.LogDebug("已清理過期對話",
+			zap.Int("removed", removed),
+			zap.Int("remaining", len(s.sessions)),
+		)
+	}
+}
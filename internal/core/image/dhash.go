@@ -0,0 +1,90 @@
+package image
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"math/bits"
+	"strings"
+)
+
+// dHash 縮圖尺寸：9x8 灰階，每列相鄰像素比較得到 8 個位元
+const (
+	dhashWidth  = 9
+	dhashHeight = 8
+	// dhashSamples 每個縮圖格子在每個方向的取樣點數，限制大圖的計算量
+	dhashSamples = 8
+)
+
+// DHash 計算 data URI 圖片的差異哈希（dHash）：縮為 9x8 灰階後比較每列相鄰像素的亮度，
+// 視覺上相近的圖片（重新壓縮、輕微晃動）哈希的漢明距離很小
+func DHash(imageData string) (uint64, error) {
+	_, encoded, ok := strings.Cut(imageData, ",")
+	if !ok || !strings.HasPrefix(imageData, "data:image/") {
+		return 0, fmt.Errorf("invalid image data format")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode base64 data: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(decoded))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var gray [dhashHeight][dhashWidth]float64
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return 0, fmt.Errorf("empty image")
+	}
+	for y := 0; y < dhashHeight; y++ {
+		for x := 0; x < dhashWidth; x++ {
+			gray[y][x] = cellLuminance(img, b, x, y)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < dhashHeight; y++ {
+		for x := 0; x < dhashWidth-1; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, nil
+}
+
+// HammingDistance 兩個哈希不同的位元數
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// cellLuminance 以格子內均勻分布的取樣點計算平均亮度
+func cellLuminance(img image.Image, b image.Rectangle, cx, cy int) float64 {
+	x0 := b.Min.X + cx*b.Dx()/dhashWidth
+	x1 := b.Min.X + (cx+1)*b.Dx()/dhashWidth
+	y0 := b.Min.Y + cy*b.Dy()/dhashHeight
+	y1 := b.Min.Y + (cy+1)*b.Dy()/dhashHeight
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+
+	var sum float64
+	var n int
+	for sy := 0; sy < dhashSamples; sy++ {
+		y := y0 + (y1-y0)*(2*sy+1)/(2*dhashSamples)
+		for sx := 0; sx < dhashSamples; sx++ {
+			x := x0 + (x1-x0)*(2*sx+1)/(2*dhashSamples)
+			r, g, bl, _ := img.At(x, y).RGBA()
+			// ITU-R BT.601 亮度
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+			n++
+		}
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound 工作不存在或已超過保留期限
+	ErrJobNotFound = errors.New("job not found")
+	// ErrTooManyJobs 保留中的工作已達上限且皆未完成
+	ErrTooManyJobs = errors.New("too many jobs")
+)
+
+// 工作狀態
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// 回呼狀態
+const (
+	CallbackPending   = "pending"
+	CallbackDelivered = "delivered"
+	CallbackFailed    = "failed"
+)
+
+// Result 工作執行結果：端點原本會回傳的狀態碼與 JSON 內容
+type Result struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Job 非同步工作
+type Job struct {
+	mu sync.Mutex
+
+	ID          string
+	Endpoint    string // 例如 POST /api/v1/recipe/food
+	RequestID   string
+	Status      string
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	CompletedAt time.Time
+	ExpiresAt   time.Time // 完成後才設定
+	Result      *Result
+	Callback    *Callback
+}
+
+// Callback 工作完成時的 webhook 回呼
+type Callback struct {
+	URL       string `json:"url"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// View 工作的 JSON 快照
+type View struct {
+	ID          string          `json:"id"`
+	Endpoint    string          `json:"endpoint"`
+	RequestID   string          `json:"request_id,omitempty"`
+	Status      string          `json:"status"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"`
+	HTTPStatus  int             `json:"http_status,omitempty"` // 端點原本會回傳的狀態碼
+	Result      json.RawMessage `json:"result,omitempty"`      // 成功時為端點原本的回應內容
+	Error       string          `json:"error,omitempty"`
+	Callback    *Callback       `json:"callback,omitempty"`
+}
+
+// View 獲取工作快照
+func (j *Job) View() *View {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	v := &View{
+		ID:        j.ID,
+		Endpoint:  j.Endpoint,
+		RequestID: j.RequestID,
+		Status:    j.Status,
+		CreatedAt: j.CreatedAt,
+	}
+	if !j.StartedAt.IsZero() {
+		v.StartedAt = &j.StartedAt
+	}
+	if !j.CompletedAt.IsZero() {
+		v.CompletedAt = &j.CompletedAt
+		v.ExpiresAt = &j.ExpiresAt
+	}
+	if j.Result != nil {
+		v.HTTPStatus = j.Result.StatusCode
+		if j.Status == StatusSucceeded {
+			v.Result = json.RawMessage(j.Result.Body)
+		} else {
+			v.Error = errorMessage(j.Result.Body)
+		}
+	}
+	if j.Callback != nil {
+		cb := *j.Callback
+		v.Callback = &cb
+	}
+	return v
+}
+
+// done 檢查工作是否已完成，呼叫端需持有 j.mu
+func (j *Job) done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// errorMessage 取出錯誤回應中的 error 欄位，非 JSON 時直接使用內容
+func errorMessage(body []byte) string {
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		return payload.Error
+	}
+	return string(body)
+}
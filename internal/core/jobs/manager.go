@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"recipe-generator/internal/infrastructure/config"
+	"recipe-generator/internal/pkg/common"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RunFunc 執行工作並返回端點原本會回傳的結果
+type RunFunc func(ctx context.Context) Result
+
+// Spec 建立工作所需的資訊
+type Spec struct {
+	Endpoint    string
+	RequestID   string
+	CallbackURL string // 選填，完成時以 HMAC 簽章 POST 工作內容
+}
+
+// Manager 非同步工作管理器：在背景執行工作並保留結果至 Retention 期滿
+type Manager struct {
+	config config.JobsConfig
+	store  *Store
+	client *http.Client
+}
+
+// NewManager 創建工作管理器
+func NewManager(cfg config.JobsConfig) *Manager {
+	interval := cfg.Retention / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	return &Manager{
+		config: cfg,
+		store:  NewStore(cfg.MaxJobs, interval),
+		client: newCallbackClient(),
+	}
+}
+
+// Submit 建立工作並在背景執行 run。工作使用與 ctx 脫鉤的 context（保留其中的值、
+// 不隨請求取消），執行時間上限為 Timeout
+func (m *Manager) Submit(ctx context.Context, spec Spec, run RunFunc) (*Job, error) {
+	job := &Job{
+		ID:        uuid.New().String(),
+		Endpoint:  spec.Endpoint,
+		RequestID: spec.RequestID,
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+	}
+	if spec.CallbackURL != "" {
+		if m.config.WebhookSecret == "" {
+			return nil, ErrCallbackDisabled
+		}
+		lookupCtx, cancel := context.WithTimeout(ctx, m.config.WebhookTimeout)
+		err := validateCallback(lookupCtx, spec.CallbackURL)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		job.Callback = &Callback{URL: spec.CallbackURL, Status: CallbackPending}
+	}
+	if err := m.store.Add(job); err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.Timeout)
+	go func() {
+		defer cancel()
+		m.run(jobCtx, job, run)
+	}()
+
+	common.LogInfo("已建立非同步工作",
+		zap.String("job_id", job.ID),
+		zap.String("endpoint", job.Endpoint),
+		zap.String("request_id", job.RequestID),
+		zap.Bool("callback", job.Callback != nil),
+	)
+	return job, nil
+}
+
+// Get 獲取未過期的工作
+func (m *Manager) Get(id string) (*Job, error) {
+	job, ok := m.store.Get(id)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Close 停止過期清理
+func (m *Manager) Close() {
+	m.store.Close()
+}
+
+// run 執行工作、保存結果並送出回呼
+func (m *Manager) run(ctx context.Context, job *Job, run RunFunc) {
+	job.mu.Lock()
+	job.Status = StatusRunning
+	job.StartedAt = time.Now()
+	job.mu.Unlock()
+
+	result := m.safeRun(ctx, job.ID, run)
+
+	job.mu.Lock()
+	job.Result = &result
+	job.Status = StatusFailed
+	if result.StatusCode >= 200 && result.StatusCode < 300 {
+		job.Status = StatusSucceeded
+	}
+	job.CompletedAt = time.Now()
+	job.ExpiresAt = job.CompletedAt.Add(m.config.Retention)
+	status := job.Status
+	duration := job.CompletedAt.Sub(job.StartedAt)
+	hasCallback := job.Callback != nil
+	job.mu.Unlock()
+
+	common.LogInfo("非同步工作已完成",
+		zap.String("job_id", job.ID),
+		zap.String("status", status),
+		zap.Int("http_status", result.StatusCode),
+		zap.Duration("duration", duration),
+	)
+
+	if hasCallback {
+		m.deliver(job)
+	}
+}
+
+// safeRun 執行工作，發生 panic 時視為 500
+func (m *Manager) safeRun(ctx context.Context, jobID string, run RunFunc) (result Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			common.LogError("非同步工作發生 panic",
+				zap.String("job_id", jobID),
+				zap.Any("panic", r),
+			)
+			result = Result{
+				StatusCode: http.StatusInternalServerError,
+				Body:       []byte(`{"error":"Internal server error"}`),
+			}
+		}
+	}()
+	return run(ctx)
+}
@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// Store 記憶體中的工作儲存，完成超過保留期限的工作會被移除
+type Store struct {
+	mu        sync.Mutex
+	jobs      map[string]*Job
+	maxJobs   int
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStore 創建工作儲存並啟動過期清理
+func NewStore(maxJobs int, cleanupInterval time.Duration) *Store {
+	s := &Store{
+		jobs:    make(map[string]*Job),
+		maxJobs: maxJobs,
+		done:    make(chan struct{}),
+	}
+	go s.startCleanup(cleanupInterval)
+	return s
+}
+
+// Add 加入新工作；達到上限時移除最早過期的已完成工作，皆未完成時返回 ErrTooManyJobs
+func (s *Store) Add(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.jobs) >= s.maxJobs && !s.evictLocked() {
+		return ErrTooManyJobs
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get 獲取未過期的工作
+func (s *Store) Get(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	if expired(job, time.Now()) {
+		delete(s.jobs, id)
+		return nil, false
+	}
+	return job, true
+}
+
+// Len 獲取目前保留的工作數
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.jobs)
+}
+
+// Close 停止過期清理
+func (s *Store) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// expired 檢查工作是否已完成且超過保留期限
+func expired(job *Job, now time.Time) bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	return job.done() && now.After(job.ExpiresAt)
+}
+
+// evictLocked 移除最早過期的已完成工作，呼叫端需持有 s.mu
+func (s *Store) evictLocked() bool {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+	for id, job := range s.jobs {
+		job.mu.Lock()
+		if job.done() && (oldestID == "" || job.ExpiresAt.Before(oldest)) {
+			oldestID, oldest = id, job.ExpiresAt
+		}
+		job.mu.Unlock()
+	}
+	if oldestID == "" {
+		return false
+	}
+	delete(s.jobs, oldestID)
+	common.LogWarn("工作數已達上限，提前移除已完成的工作",
+		zap.String("job_id", oldestID),
+		zap.Int("max_jobs", s.maxJobs),
+	)
+	return true
+}
+
+// startCleanup 定期移除過期工作
+func (s *Store) startCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.cleanup()
+		}
+	}
+}
+
+// cleanup 移除所有過期工作
+func (s *Store) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for id, job := range s.jobs {
+		if expired(job, now) {
+			delete(s.jobs, id)
+			removed++
+		}
+	}
+	if removed > 0 {
+		common.LogDebug("已清理過期工作",
+			zap.Int("removed", removed),
+			zap.Int("remaining", len(s.jobs)),
+		)
+	}
+}
@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// webhook 請求頭
+const (
+	SignatureHeader = "X-Webhook-Signature" // sha256=<hex>
+	TimestampHeader = "X-Webhook-Timestamp" // Unix 秒
+	JobIDHeader     = "X-Webhook-Job-ID"
+)
+
+// ErrInvalidCallback 回呼網址不是絕對的 http(s) 網址
+var ErrInvalidCallback = errors.New("callback url must be an absolute http or https url")
+
+// ErrCallbackForbidden 回呼網址指向迴環、私有、鏈路本地或未指定位址
+var ErrCallbackForbidden = errors.New("callback host must resolve to a public address")
+
+// ErrCallbackDisabled 未設定簽章金鑰，不接受回呼網址
+var ErrCallbackDisabled = errors.New("webhook callbacks are disabled")
+
+// Sign 計算 webhook 簽章：HMAC-SHA256(secret, timestamp + "." + body)，以 sha256=<hex> 表示
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 驗證 webhook 簽章，供接收端使用
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// validateCallback 檢查回呼網址，並解析主機確認所有位址皆為公開位址，避免伺服器代為請求內部服務；
+// 送出時 newCallbackClient 會在連線前再次檢查，防止 DNS 重新綁定
+func validateCallback(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidCallback
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve host %q", ErrInvalidCallback, u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrCallbackForbidden
+		}
+	}
+	return nil
+}
+
+// publicIP 判斷是否為可作為回呼目標的位址：排除迴環、私有（RFC 1918、fc00::/7）、
+// 鏈路本地（含 169.254.169.254 雲端中繼資料服務）、未指定與多播位址
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// newCallbackClient 創建回呼用的 HTTP 客戶端：不經過環境變數設定的代理，
+// 並在每次連線（含重新導向）前檢查實際連線的位址
+func newCallbackClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return ErrCallbackForbidden
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// deliver 將已完成的工作 POST 至回呼網址，失敗時以指數退避重試
+func (m *Manager) deliver(job *Job) {
+	view := job.View()
+	body, err := json.Marshal(view)
+	if err != nil {
+		common.LogError("工作回呼內容編碼失敗", zap.Error(err), zap.String("job_id", view.ID))
+		return
+	}
+
+	delay := time.Second
+	for attempt := 1; attempt <= m.config.WebhookRetries+1; attempt++ {
+		err = m.post(view.Callback.URL, view.ID, body)
+
+		job.mu.Lock()
+		job.Callback.Attempts = attempt
+		if err == nil {
+			job.Callback.Status = CallbackDelivered
+			job.Callback.LastError = ""
+		} else {
+			job.Callback.LastError = err.Error()
+		}
+		job.mu.Unlock()
+
+		if err == nil {
+			common.LogInfo("工作回呼已送達",
+				zap.String("job_id", view.ID),
+				zap.Int("attempts", attempt),
+			)
+			return
+		}
+		common.LogWarn("工作回呼失敗",
+			zap.String("job_id", view.ID),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		if attempt <= m.config.WebhookRetries {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	job.mu.Lock()
+	job.Callback.Status = CallbackFailed
+	job.mu.Unlock()
+}
+
+// post 送出一次已簽章的回呼請求，2xx 以外的狀態碼視為失敗
+func (m *Manager) post(callbackURL, jobID string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(JobIDHeader, jobID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(m.config.WebhookSecret, timestamp, body))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/locale"
+
+	"go.uber.org/zap"
+)
+
+//go:embed templates/*.tmpl
+var embedded embed.FS
+
+// 模板名稱
+const (
+	FoodRecognition       = "food_recognition"
+	IngredientRecognition = "ingredient_recognition"
+	RecipeGeneration      = "recipe_generation"
+	RecipeSuggestion      = "recipe_suggestion"
+)
+
+// versionedFile 版本化模板檔名：<name>[.<locale>].v<version>.tmpl；其他 .tmpl 檔視為共用片段
+var versionedFile = regexp.MustCompile(`^([a-z0-9_]+)(?:\.([A-Za-z]{2}(?:-[A-Za-z]{2})?))?\.v([0-9]+)\.tmpl$`)
+
+// funcs 模板可用的函式
+var funcs = template.FuncMap{
+	"join":     strings.Join,
+	"language": locale.Language,
+}
+
+// Rendered 渲染完成的 prompt 與其來源模板
+type Rendered struct {
+	Name    string
+	Locale  string // 使用語系專屬模板時的語系，通用模板為空
+	Version int
+	Text    string
+}
+
+// ID 模板識別，格式為 name@v<version>，語系專屬模板為 name.<locale>@v<version>
+func (r *Rendered) ID() string {
+	if r.Locale != "" {
+		return fmt.Sprintf("%s.%s@v%d", r.Name, r.Locale, r.Version)
+	}
+	return fmt.Sprintf("%s@v%d", r.Name, r.Version)
+}
+
+// Registry 版本化 prompt 模板註冊表
+type Registry struct {
+	templates map[string]map[int]*template.Template // 鍵為 name 或 name.<locale>
+	active    map[string]int
+}
+
+// NewRegistry 載入內嵌模板，並以 overrideDir 中的同名檔案覆蓋或新增版本；
+// pinned 指定各模板使用的版本，未指定者使用最新版本。
+// 語系專屬模板（name.<locale>.vN.tmpl）為同版本通用模板的翻譯，不影響版本選擇
+func NewRegistry(overrideDir string, pinned map[string]int) (*Registry, error) {
+	partials := make(map[string]string)
+	versions := make(map[string]map[int]string)
+
+	add := func(fsys fs.FS, source string) error {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".tmpl" {
+				continue
+			}
+			data, err := fs.ReadFile(fsys, entry.Name())
+			if err != nil {
+				return err
+			}
+
+			m := versionedFile.FindStringSubmatch(entry.Name())
+			if m == nil {
+				partials[entry.Name()] = string(data)
+				continue
+			}
+			key := m[1]
+			if m[2] != "" {
+				tag, ok := locale.Match(m[2])
+				if !ok || !strings.EqualFold(tag, m[2]) {
+					return fmt.Errorf("unsupported prompt locale in %s", entry.Name())
+				}
+				key += "." + tag
+			}
+			version, _ := strconv.Atoi(m[3])
+			if versions[key] == nil {
+				versions[key] = make(map[int]string)
+			}
+			versions[key][version] = string(data)
+			common.LogDebug("已載入 prompt 模板",
+				zap.String("template", key),
+				zap.Int("version", version),
+				zap.String("source", source),
+			)
+		}
+		return nil
+	}
+
+	sub, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := add(sub, "embedded"); err != nil {
+		return nil, fmt.Errorf("failed to load embedded prompts: %w", err)
+	}
+	if overrideDir != "" {
+		if _, err := os.Stat(overrideDir); err == nil {
+			if err := add(os.DirFS(overrideDir), overrideDir); err != nil {
+				return nil, fmt.Errorf("failed to load prompts from %s: %w", overrideDir, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read prompt dir %s: %w", overrideDir, err)
+		}
+	}
+
+	// 共用片段依檔名排序後附加至每個模板，確保覆蓋結果一致
+	partialNames := make([]string, 0, len(partials))
+	for name := range partials {
+		partialNames = append(partialNames, name)
+	}
+	sort.Strings(partialNames)
+
+	r := &Registry{
+		templates: make(map[string]map[int]*template.Template),
+		active:    make(map[string]int),
+	}
+	for key, byVersion := range versions {
+		name, tag, localized := strings.Cut(key, ".")
+		r.templates[key] = make(map[int]*template.Template)
+		for version, text := range byVersion {
+			if localized {
+				if _, ok := versions[name][version]; !ok {
+					return nil, fmt.Errorf("prompt %s (%s) v%d has no matching base template", name, tag, version)
+				}
+			}
+			tmpl := template.New(key).Funcs(funcs).Option("missingkey=error")
+			for _, partial := range partialNames {
+				if _, err := tmpl.New(partial).Parse(partials[partial]); err != nil {
+					return nil, fmt.Errorf("failed to parse prompt partial %s: %w", partial, err)
+				}
+			}
+			if _, err := tmpl.Parse(text); err != nil {
+				return nil, fmt.Errorf("failed to parse prompt %s v%d: %w", key, version, err)
+			}
+			r.templates[key][version] = tmpl
+			if !localized && version > r.active[name] {
+				r.active[name] = version
+			}
+		}
+	}
+
+	for name, version := range pinned {
+		if _, ok := r.templates[name][version]; !ok {
+			return nil, fmt.Errorf("prompt %s has no version %d", name, version)
+		}
+		r.active[name] = version
+	}
+
+	common.LogInfo("Prompt 模板已載入",
+		zap.Any("active", r.active),
+		zap.String("override_dir", overrideDir),
+	)
+	return r, nil
+}
+
+// Render 以目前使用的版本渲染模板，有該語系專屬模板時優先使用
+func (r *Registry) Render(name, tag string, data interface{}) (*Rendered, error) {
+	version, ok := r.active[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt template: %s", name)
+	}
+	return r.RenderVersion(name, tag, version, data)
+}
+
+// RenderVersion 渲染指定版本的模板，有該語系專屬模板時優先使用
+func (r *Registry) RenderVersion(name, tag string, version int, data interface{}) (*Rendered, error) {
+	rendered := &Rendered{Name: name, Version: version}
+	tmpl, ok := r.templates[name+"."+tag][version]
+	if ok {
+		rendered.Locale = tag
+	} else if tmpl, ok = r.templates[name][version]; !ok {
+		return nil, fmt.Errorf("prompt %s has no version %d", name, version)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to render prompt %s: %w", rendered.ID(), err)
+	}
+	rendered.Text = strings.TrimSpace(sb.String())
+	return rendered, nil
+}
+
+// Active 獲取各模板目前使用的版本
+func (r *Registry) Active() map[string]int {
+	active := make(map[string]int, len(r.active))
+	for name, version := range r.active {
+		active[name] = version
+	}
+	return active
+}
+
+// ParseVersions 解析 "name=2,name2=1" 格式的版本指定
+func ParseVersions(spec string) (map[string]int, error) {
+	pinned := make(map[string]int)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid prompt version %q: expected name=version", part)
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "v"))
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid prompt version %q", part)
+		}
+		pinned[strings.TrimSpace(name)] = version
+	}
+	return pinned, nil
+}
@@ -0,0 +1,80 @@
+package recipe
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"recipe-generator/internal/pkg/common"
+)
+
+// requestFingerprint 食譜請求的標準化指紋，取代 prompt 文字作為快取鍵：
+// 食材、設備與飲食限制排序並正規化大小寫與空白，順序或格式不同但內容相同的請求共用快取
+type requestFingerprint struct {
+	Template      string   `json:"template"` // 模板與版本（name@vN），模板改版後舊快取自然失效
+	DishName      string   `json:"dish_name,omitempty"`
+	Ingredients   []string `json:"ingredients,omitempty"`
+	Equipment     []string `json:"equipment,omitempty"`
+	CookingMethod string   `json:"cooking_method"`
+	Restrictions  []string `json:"restrictions,omitempty"`
+	ServingSize   string   `json:"serving_size"`
+}
+
+// key 序列化為快取鍵
+func (f *requestFingerprint) key() string {
+	data, _ := json.Marshal(f)
+	return "fingerprint:" + string(data)
+}
+
+// normalizeText 轉為小寫並將連續空白合併為單一空格
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// canonicalIngredients 將食材逐一正規化後排序
+func canonicalIngredients(ingredients []common.Ingredient) []string {
+	out := make([]string, 0, len(ingredients))
+	for _, ing := range ingredients {
+		out = append(out, strings.Join([]string{
+			normalizeText(ing.Name),
+			normalizeText(ing.Type),
+			normalizeText(ing.Amount),
+			normalizeText(ing.Unit),
+			normalizeText(ing.Preparation),
+		}, "|"))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// canonicalEquipment 將設備逐一正規化後排序
+func canonicalEquipment(equipment []common.Equipment) []string {
+	out := make([]string, 0, len(equipment))
+	for _, equip := range equipment {
+		out = append(out, strings.Join([]string{
+			normalizeText(equip.Name),
+			normalizeText(equip.Type),
+			normalizeText(equip.Size),
+			normalizeText(equip.Material),
+			normalizeText(equip.PowerSource),
+		}, "|"))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// canonicalSet 正規化後排序並去除空白與重複項目，用於飲食限制等集合語意的欄位
+func canonicalSet(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = normalizeText(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
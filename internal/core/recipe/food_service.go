@@ -11,8 +11,11 @@ import (
 	"time"
 
 	"recipe-generator/internal/core/ai/cache"
+	"recipe-generator/internal/core/ai/provider"
 	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/prompt"
 	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
 
 	"go.uber.org/zap"
 )
@@ -20,14 +23,16 @@ import (
 // FoodService 食物識別服務
 type FoodService struct {
 	aiService    *service.Service
-	cacheManager *cache.CacheManager
+	cacheManager cache.Cache
+	prompts      *prompt.Registry
 }
 
 // NewFoodService 創建新的食物識別服務
-func NewFoodService(aiService *service.Service, cacheManager *cache.CacheManager) *FoodService {
+func NewFoodService(aiService *service.Service, cacheManager cache.Cache, prompts *prompt.Registry) *FoodService {
 	return &FoodService{
 		aiService:    aiService,
 		cacheManager: cacheManager,
+		prompts:      prompts,
 	}
 }
 
@@ -75,52 +80,23 @@ func saveRequestData(prompt string, imageData string) error {
 	return nil
 }
 
-// IdentifyFood 識別圖片中的食物
-func (s *FoodService) IdentifyFood(ctx context.Context, imageData string, descriptionHint string) (*common.FoodRecognitionResult, error) {
+// IdentifyFood 識別圖片中的食物，結果以指定語系描述
+func (s *FoodService) IdentifyFood(ctx context.Context, imageData string, descriptionHint string, lang string) (*common.FoodRecognitionResult, error) {
 	// 記錄請求信息
 	common.LogInfo("開始處理食物識別請求",
 		zap.String("image_type", getImageType(imageData)),
 		zap.String("description_hint", descriptionHint),
+		zap.String("locale", lang),
 	)
 
 	// 構建提示詞
-	prompt := fmt.Sprintf(`請仔細分析圖片中的食物，並以 JSON 格式返回結果(並且用繁體中文回答）。要求：
-1. 只識別圖片中實際可見的食物
-2. 不要添加圖片中未出現的食物
-3. 如果無法確定某個屬性，請使用 "未知" 而不是猜測
-4. 所有欄位必須使用雙引號
-5. 不要使用預設值或猜測值
-6. 請確保識別結果與圖片內容完全相符
-7. 如果圖片中沒有食物，請返回空列表
-8. 不要使用\n，不需要換行
-9. 根據辨識到的食物給出推論後可能需要用到的食材與製作廚具
-10. 不需要考慮可讀性，請省略所有空格和換行，返回最緊湊的 JSON 格式
-11. 所有欄位都必須要有不能漏掉，如果不知道填什麼請留空 "" or null
-請以以下 JSON 格式返回：
-{
-    "recognized_foods": [
-        {
-            "name": "食物名稱",
-					"description": "此食物的特徵與可能料理方式說明",
-            "possible_ingredients": [
-                {
-                    "name": "食材名稱",
-                    "type": "食材類型"
-                }
-            ],
-            "possible_equipment": [
-                {
-                    "name": "設備名稱",
-                    "type": "設備類型"
-                }
-            ]
-        }
-    ]
-}
-%s`, descriptionHint)
+	rendered, err := s.prompts.Render(prompt.FoodRecognition, lang, recognitionPromptData{DescriptionHint: descriptionHint, Locale: lang})
+	if err != nil {
+		return nil, err
+	}
 
 	// 保存請求數據
-	// if err := saveRequestData(prompt, imageData); err != nil {
+	// if err := saveRequestData(rendered.Text, imageData); err != nil {
 	// 	common.LogError("保存請求數據失敗",
 	// 		zap.Error(err),
 	// 		zap.String("image_type", getImageType(imageData)))
@@ -128,7 +104,14 @@ func (s *FoodService) IdentifyFood(ctx context.Context, imageData string, descri
 	// }
 
 	// 調用 AI 服務
-	response, err := s.aiService.ProcessRequest(ctx, prompt, imageData)
+	response, err := s.aiService.Process(ctx, &service.Request{
+		Task:      provider.TaskFoodRecognition,
+		Prompt:    rendered.Text,
+		ImageData: imageData,
+		Schema:    common.FoodRecognitionSchema,
+		Template:  rendered.ID(),
+		Locale:    lang,
+	})
 	if err != nil {
 		common.LogError("AI 服務請求失敗",
 			zap.Error(err),
@@ -137,15 +120,8 @@ func (s *FoodService) IdentifyFood(ctx context.Context, imageData string, descri
 	}
 
 	// 解析響應
-	content := response.Content
-	content = strings.TrimSpace(content)
-	start := strings.Index(content, "{")
-	end := strings.LastIndex(content, "}")
-	if start != -1 && end != -1 && end > start {
-		content = content[start : end+1]
-	}
 	var result common.FoodRecognitionResult
-	if err := common.ParseJSON(content, &result); err != nil {
+	if err := common.ParseJSON(schema.ExtractJSON(response.Content), &result); err != nil {
 		common.LogError("AI 響應解析失敗",
 			zap.Error(err),
 		)
@@ -153,31 +129,32 @@ func (s *FoodService) IdentifyFood(ctx context.Context, imageData string, descri
 	}
 
 	// 檢查並補充空值
+	text := fallbacksFor(lang)
 	for i := range result.RecognizedFoods {
 		if result.RecognizedFoods[i].Name == "" {
-			result.RecognizedFoods[i].Name = "未知食物"
+			result.RecognizedFoods[i].Name = text.UnknownFood
 		}
 		if result.RecognizedFoods[i].Description == "" {
-			result.RecognizedFoods[i].Description = "無描述"
+			result.RecognizedFoods[i].Description = text.NoDescription
 		}
 
 		// 檢查並補充可能的食材
 		for j := range result.RecognizedFoods[i].PossibleIngredients {
 			if result.RecognizedFoods[i].PossibleIngredients[j].Name == "" {
-				result.RecognizedFoods[i].PossibleIngredients[j].Name = "未知食材"
+				result.RecognizedFoods[i].PossibleIngredients[j].Name = text.UnknownIngredient
 			}
 			if result.RecognizedFoods[i].PossibleIngredients[j].Type == "" {
-				result.RecognizedFoods[i].PossibleIngredients[j].Type = "未知類型"
+				result.RecognizedFoods[i].PossibleIngredients[j].Type = text.UnknownType
 			}
 		}
 
 		// 檢查並補充可能的設備
 		for j := range result.RecognizedFoods[i].PossibleEquipment {
 			if result.RecognizedFoods[i].PossibleEquipment[j].Name == "" {
-				result.RecognizedFoods[i].PossibleEquipment[j].Name = "未知設備"
+				result.RecognizedFoods[i].PossibleEquipment[j].Name = text.UnknownEquipment
 			}
 			if result.RecognizedFoods[i].PossibleEquipment[j].Type == "" {
-				result.RecognizedFoods[i].PossibleEquipment[j].Type = "未知類型"
+				result.RecognizedFoods[i].PossibleEquipment[j].Type = text.UnknownType
 			}
 		}
 	}
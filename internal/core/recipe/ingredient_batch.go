@@ -0,0 +1,127 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"recipe-generator/internal/pkg/common"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidImage 圖片不是 data:image/ 格式
+var ErrInvalidImage = errors.New("invalid image format")
+
+// BatchItem 批次辨識中單張圖片的結果，Result 與 Err 擇一
+type BatchItem struct {
+	Index  int
+	Result *common.IngredientRecognitionResult
+	Err    error
+}
+
+// MergedIngredient 合併後的食材與其出現的圖片索引
+type MergedIngredient struct {
+	common.Ingredient
+	Images []int
+}
+
+// MergedEquipment 合併後的設備與其出現的圖片索引
+type MergedEquipment struct {
+	common.Equipment
+	Images []int
+}
+
+// IdentifyIngredientBatch 以最多 concurrency 張同時進行的方式辨識多張圖片，
+// 依輸入順序返回每張圖片的結果或錯誤；單張失敗不影響其他圖片
+func (s *IngredientService) IdentifyIngredientBatch(ctx context.Context, images []string, lang string, concurrency int) []BatchItem {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	items := make([]BatchItem, len(images))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, img := range images {
+		items[i].Index = i
+		if !strings.HasPrefix(img, "data:image/") {
+			items[i].Err = ErrInvalidImage
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, img string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				items[i].Err = ctx.Err()
+				return
+			}
+			items[i].Result, items[i].Err = s.IdentifyIngredient(ctx, img, lang)
+		}(i, img)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, item := range items {
+		if item.Err != nil {
+			failed++
+		}
+	}
+	common.LogInfo("批次食材辨識完成",
+		zap.Int("images", len(images)),
+		zap.Int("failed", failed),
+		zap.Int("concurrency", concurrency),
+	)
+	return items
+}
+
+// MergeBatch 合併所有成功結果中的食材與設備，依名稱（忽略大小寫與空白）去除重複，
+// 保留第一次出現的內容並記錄出現的圖片索引
+func MergeBatch(items []BatchItem) ([]MergedIngredient, []MergedEquipment) {
+	ingredients := []MergedIngredient{}
+	equipment := []MergedEquipment{}
+	ingredientIndex := make(map[string]int)
+	equipmentIndex := make(map[string]int)
+
+	for _, item := range items {
+		if item.Result == nil {
+			continue
+		}
+		for _, ing := range item.Result.Ingredients {
+			key := mergeKey(ing.Name)
+			if pos, ok := ingredientIndex[key]; ok {
+				ingredients[pos].Images = appendImage(ingredients[pos].Images, item.Index)
+				continue
+			}
+			ingredientIndex[key] = len(ingredients)
+			ingredients = append(ingredients, MergedIngredient{Ingredient: ing, Images: []int{item.Index}})
+		}
+		for _, equip := range item.Result.Equipment {
+			key := mergeKey(equip.Name)
+			if pos, ok := equipmentIndex[key]; ok {
+				equipment[pos].Images = appendImage(equipment[pos].Images, item.Index)
+				continue
+			}
+			equipmentIndex[key] = len(equipment)
+			equipment = append(equipment, MergedEquipment{Equipment: equip, Images: []int{item.Index}})
+		}
+	}
+	return ingredients, equipment
+}
+
+// mergeKey 合併用的名稱鍵：去除所有空白並轉為小寫
+func mergeKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+// appendImage 加入圖片索引，同一張圖片重複出現時只記錄一次
+func appendImage(images []int, index int) []int {
+	if len(images) > 0 && images[len(images)-1] == index {
+		return images
+	}
+	return append(images, index)
+}
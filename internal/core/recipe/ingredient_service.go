@@ -3,12 +3,14 @@ package recipe
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"recipe-generator/internal/core/ai/cache"
 	"recipe-generator/internal/core/ai/image"
+	"recipe-generator/internal/core/ai/provider"
 	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/core/prompt"
 	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
 
 	"go.uber.org/zap"
 )
@@ -16,21 +18,23 @@ import (
 // IngredientService 食材識別服務
 type IngredientService struct {
 	aiService    *service.Service
-	cacheManager *cache.CacheManager
+	cacheManager cache.Cache
 	imageService *image.Processor
+	prompts      *prompt.Registry
 }
 
 // NewIngredientService 創建新的食材識別服務
-func NewIngredientService(aiService *service.Service, cacheManager *cache.CacheManager, imageService *image.Processor) *IngredientService {
+func NewIngredientService(aiService *service.Service, cacheManager cache.Cache, imageService *image.Processor, prompts *prompt.Registry) *IngredientService {
 	return &IngredientService{
 		aiService:    aiService,
 		cacheManager: cacheManager,
 		imageService: imageService,
+		prompts:      prompts,
 	}
 }
 
-// IdentifyIngredient 識別圖片中的食材和設備
-func (s *IngredientService) IdentifyIngredient(ctx context.Context, imageData string) (*common.IngredientRecognitionResult, error) {
+// IdentifyIngredient 識別圖片中的食材和設備，結果以指定語系描述
+func (s *IngredientService) IdentifyIngredient(ctx context.Context, imageData string, lang string) (*common.IngredientRecognitionResult, error) {
 	// 驗證圖片
 	if imageData == "" {
 		return nil, fmt.Errorf("invalid image: image data is empty")
@@ -43,98 +47,71 @@ func (s *IngredientService) IdentifyIngredient(ctx context.Context, imageData st
 	}
 
 	// 構建提示
-	prompt := `請仔細分析圖片中的食材和設備，並提供詳細的識別結果(並且用繁體中文回答）(不需要考慮可讀性，請省略所有空格和換行，返回最緊湊的 JSON 格式)。
-		要求：
-		1. 只識別圖片中實際可見的食材和設備
-		2. 不要添加圖片中未出現的物品
-		3. 根據圖片內容判斷數量、單位和處理方式
-		4. 如果無法確定某個屬性，請使用 "未知" 而不是猜測
-		5. 所有欄位必須使用雙引號
-		6. 不要使用預設值或猜測值
-		7. 不要使用\n，不需要換行
-		8. 不需要考慮可讀性，請省略所有空格和換行，返回最緊湊的 JSON 格式
-		請以以下 JSON 格式返回：
-		{
-			"ingredients": [
-				{
-					"name": "食材名稱",
-					"type": "食材類型",
-					"amount": "數量",
-					"unit": "單位",
-					"preparation": "處理方式"
-				}
-			],
-			"equipment": [
-				{
-					"name": "設備名稱",
-					"type": "設備類型",
-					"size": "尺寸",
-					"material": "材質",
-					"power_source": "能源類型"
-				}
-			],
-			"summary": "辨識內容摘要，方便使用者核對確認"
-		}`
+	rendered, err := s.prompts.Render(prompt.IngredientRecognition, lang, recognitionPromptData{Locale: lang})
+	if err != nil {
+		return nil, err
+	}
 
 	// 發送請求到 AI 服務
-	response, err := s.aiService.ProcessRequest(ctx, prompt, processedImage)
+	response, err := s.aiService.Process(ctx, &service.Request{
+		Task:      provider.TaskIngredientRecognition,
+		Prompt:    rendered.Text,
+		ImageData: processedImage,
+		Schema:    common.IngredientRecognitionSchema,
+		Template:  rendered.ID(),
+		Locale:    lang,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to process request: %w", err)
 	}
 
 	// 解析響應
-	content := response.Content
-	content = strings.TrimSpace(content)
-	start := strings.Index(content, "{")
-	end := strings.LastIndex(content, "}")
-	if start != -1 && end != -1 && end > start {
-		content = content[start : end+1]
-	}
 	var result common.IngredientRecognitionResult
-	if err := common.ParseJSON(content, &result); err != nil {
+	if err := common.ParseJSON(schema.ExtractJSON(response.Content), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
 
 	// 檢查並補充空值
+	text := fallbacksFor(lang)
 	if result.Summary == "" {
-		result.Summary = "無摘要"
+		result.Summary = text.NoSummary
 	}
 
 	// 檢查並補充食材資訊
 	for i := range result.Ingredients {
 		if result.Ingredients[i].Name == "" {
-			result.Ingredients[i].Name = "未知食材"
+			result.Ingredients[i].Name = text.UnknownIngredient
 		}
 		if result.Ingredients[i].Type == "" {
-			result.Ingredients[i].Type = "未知類型"
+			result.Ingredients[i].Type = text.UnknownType
 		}
 		if result.Ingredients[i].Amount == "" {
-			result.Ingredients[i].Amount = "適量"
+			result.Ingredients[i].Amount = text.Amount
 		}
 		if result.Ingredients[i].Unit == "" {
-			result.Ingredients[i].Unit = "份"
+			result.Ingredients[i].Unit = text.Unit
 		}
 		if result.Ingredients[i].Preparation == "" {
-			result.Ingredients[i].Preparation = "無特殊處理"
+			result.Ingredients[i].Preparation = text.NoPreparation
 		}
 	}
 
 	// 檢查並補充設備資訊
 	for i := range result.Equipment {
 		if result.Equipment[i].Name == "" {
-			result.Equipment[i].Name = "未知設備"
+			result.Equipment[i].Name = text.UnknownEquipment
 		}
 		if result.Equipment[i].Type == "" {
-			result.Equipment[i].Type = "未知類型"
+			result.Equipment[i].Type = text.UnknownType
 		}
 		if result.Equipment[i].Size == "" {
-			result.Equipment[i].Size = "標準"
+			result.Equipment[i].Size = text.Size
 		}
 		if result.Equipment[i].Material == "" {
-			result.Equipment[i].Material = "未知"
+			result.Equipment[i].Material = text.Unknown
 		}
 		if result.Equipment[i].PowerSource == "" {
-			result.Equipment[i].PowerSource = "未知"
+			result.Equipment[i].PowerSource = text.Unknown
 		}
 	}
 
@@ -146,42 +123,29 @@ func (s *IngredientService) IdentifyIngredient(ctx context.Context, imageData st
 	return &result, nil
 }
 
-func (s *IngredientService) IdentifyIngredients(ctx context.Context, imageData string, descriptionHint string) (*common.IngredientRecognitionResult, error) {
+func (s *IngredientService) IdentifyIngredients(ctx context.Context, imageData string, descriptionHint string, lang string) (*common.IngredientRecognitionResult, error) {
 	// 構建提示詞
-	prompt := fmt.Sprintf(`請分析圖片中的食材和設備，並以 JSON 格式返回結果。格式如下：
-{
-    "ingredients": [
-        {
-            "name": "食材名稱",
-            "type": "食材類型",
-            "amount": "數量",
-            "unit": "單位",
-            "preparation": "處理方式"
-        }
-    ],
-    "equipment": [
-        {
-            "name": "設備名稱",
-            "type": "設備類型",
-            "size": "尺寸",
-            "material": "材質",
-            "power_source": "電源類型"
-        }
-    ],
-    "summary": "識別內容摘要"
-}
-
-%s`, descriptionHint)
+	rendered, err := s.prompts.Render(prompt.IngredientRecognition, lang, recognitionPromptData{DescriptionHint: descriptionHint, Locale: lang})
+	if err != nil {
+		return nil, err
+	}
 
 	// 調用 AI 服務
-	response, err := s.aiService.ProcessRequest(ctx, prompt, imageData)
+	response, err := s.aiService.Process(ctx, &service.Request{
+		Task:      provider.TaskIngredientRecognition,
+		Prompt:    rendered.Text,
+		ImageData: imageData,
+		Schema:    common.IngredientRecognitionSchema,
+		Template:  rendered.ID(),
+		Locale:    lang,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to process request: %w", err)
 	}
 
 	// 解析響應
 	var result common.IngredientRecognitionResult
-	if err := common.ParseJSON(response.Content, &result); err != nil {
+	if err := common.ParseJSON(schema.ExtractJSON(response.Content), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
 
@@ -0,0 +1,106 @@
+package recipe
+
+import (
+	"recipe-generator/internal/pkg/locale"
+)
+
+// fallbacks 模型未填寫欄位時補上的預設文字與預設偏好
+type fallbacks struct {
+	UnknownFood       string
+	UnknownDish       string
+	UnknownIngredient string
+	UnknownEquipment  string
+	UnknownType       string
+	Unknown           string
+	NoDescription     string
+	NoSummary         string
+	NoPreparation     string
+	NoNotes           string
+	NoAction          string
+	NoDetail          string
+	None              string
+	Amount            string
+	Unit              string
+	Size              string
+	Temperature       string
+	StepTitle         string // 含一個 %d 步驟編號
+	CookingMethod     string
+	ServingSize       string
+}
+
+var localizedFallbacks = map[string]*fallbacks{
+	locale.ZhTW: {
+		UnknownFood:       "未知食物",
+		UnknownDish:       "未知菜名",
+		UnknownIngredient: "未知食材",
+		UnknownEquipment:  "未知設備",
+		UnknownType:       "未知類型",
+		Unknown:           "未知",
+		NoDescription:     "無描述",
+		NoSummary:         "無摘要",
+		NoPreparation:     "無特殊處理",
+		NoNotes:           "無備註",
+		NoAction:          "無動作",
+		NoDetail:          "無細節說明",
+		None:              "無",
+		Amount:            "適量",
+		Unit:              "份",
+		Size:              "標準",
+		Temperature:       "中火",
+		StepTitle:         "步驟 %d",
+		CookingMethod:     "炒",
+		ServingSize:       "2人份",
+	},
+	locale.En: {
+		UnknownFood:       "Unknown food",
+		UnknownDish:       "Unknown dish",
+		UnknownIngredient: "Unknown ingredient",
+		UnknownEquipment:  "Unknown equipment",
+		UnknownType:       "Unknown type",
+		Unknown:           "Unknown",
+		NoDescription:     "No description",
+		NoSummary:         "No summary",
+		NoPreparation:     "No special preparation",
+		NoNotes:           "No notes",
+		NoAction:          "No action",
+		NoDetail:          "No details",
+		None:              "None",
+		Amount:            "To taste",
+		Unit:              "portion",
+		Size:              "Standard",
+		Temperature:       "Medium heat",
+		StepTitle:         "Step %d",
+		CookingMethod:     "stir-fry",
+		ServingSize:       "2 servings",
+	},
+	locale.Ja: {
+		UnknownFood:       "不明な料理",
+		UnknownDish:       "不明な料理名",
+		UnknownIngredient: "不明な食材",
+		UnknownEquipment:  "不明な調理器具",
+		UnknownType:       "不明な種類",
+		Unknown:           "不明",
+		NoDescription:     "説明なし",
+		NoSummary:         "概要なし",
+		NoPreparation:     "下処理なし",
+		NoNotes:           "備考なし",
+		NoAction:          "動作なし",
+		NoDetail:          "詳細なし",
+		None:              "なし",
+		Amount:            "適量",
+		Unit:              "個",
+		Size:              "標準",
+		Temperature:       "中火",
+		StepTitle:         "手順 %d",
+		CookingMethod:     "炒める",
+		ServingSize:       "2人分",
+	},
+}
+
+// fallbacksFor 獲取語系對應的預設文字，不支援的語系使用預設語系
+func fallbacksFor(lang string) *fallbacks {
+	if f, ok := localizedFallbacks[lang]; ok {
+		return f
+	}
+	return localizedFallbacks[locale.Default]
+}
@@ -0,0 +1,122 @@
+package recipe
+
+import (
+	"fmt"
+
+	"recipe-generator/internal/pkg/common"
+)
+
+// parseRecipe 解析 AI 回應為食譜，依語系補充空值並驗證必要欄位
+func parseRecipe(content, lang string) (*common.Recipe, error) {
+	var result common.Recipe
+	if err := common.ParseJSON(content, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	text := fallbacksFor(lang)
+
+	// 檢查並補充空值
+	if result.DishName == "" {
+		result.DishName = text.UnknownDish
+	}
+	if result.DishDescription == "" {
+		result.DishDescription = text.NoDescription
+	}
+
+	// 檢查並補充食材資訊
+	for i := range result.Ingredients {
+		if result.Ingredients[i].Name == "" {
+			result.Ingredients[i].Name = text.UnknownIngredient
+		}
+		if result.Ingredients[i].Type == "" {
+			result.Ingredients[i].Type = text.UnknownType
+		}
+		if result.Ingredients[i].Amount == "" {
+			result.Ingredients[i].Amount = text.Amount
+		}
+		if result.Ingredients[i].Unit == "" {
+			result.Ingredients[i].Unit = text.Unit
+		}
+		if result.Ingredients[i].Preparation == "" {
+			result.Ingredients[i].Preparation = text.NoPreparation
+		}
+	}
+
+	// 檢查並補充設備資訊
+	for i := range result.Equipment {
+		if result.Equipment[i].Name == "" {
+			result.Equipment[i].Name = text.UnknownEquipment
+		}
+		if result.Equipment[i].Type == "" {
+			result.Equipment[i].Type = text.UnknownType
+		}
+		if result.Equipment[i].Size == "" {
+			result.Equipment[i].Size = text.Size
+		}
+		if result.Equipment[i].Material == "" {
+			result.Equipment[i].Material = text.Unknown
+		}
+		if result.Equipment[i].PowerSource == "" {
+			result.Equipment[i].PowerSource = text.Unknown
+		}
+	}
+
+	// 檢查並補充食譜步驟
+	for i := range result.Recipe {
+		normalizeRecipeStep(&result.Recipe[i], i, lang)
+	}
+
+	// 驗證必要欄位
+	if len(result.Recipe) == 0 {
+		return nil, fmt.Errorf("recipe steps cannot be empty")
+	}
+
+	return &result, nil
+}
+
+// normalizeRecipeStep 依語系補充食譜步驟的空值，index 為步驟在食譜中的位置（從 0 開始）
+func normalizeRecipeStep(step *common.RecipeStep, index int, lang string) {
+	text := fallbacksFor(lang)
+
+	// 確保 step_number 存在且正確
+	step.StepNumber = index + 1
+
+	if step.Title == "" {
+		step.Title = fmt.Sprintf(text.StepTitle, index+1)
+	}
+	if step.Description == "" {
+		step.Description = text.NoDescription
+	}
+	if step.EstimatedTotalTime == "" {
+		step.EstimatedTotalTime = text.Unknown
+	}
+	if step.Temperature == "" || step.Temperature == "null" {
+		step.Temperature = text.Temperature
+	}
+	if step.Warnings == "" || step.Warnings == "null" {
+		step.Warnings = text.None
+	}
+	if step.Notes == "" || step.Notes == "null" {
+		step.Notes = text.NoNotes
+	}
+
+	// 檢查並補充動作資訊
+	for j := range step.Actions {
+		if step.Actions[j].Action == "" {
+			step.Actions[j].Action = text.NoAction
+		}
+		if step.Actions[j].ToolRequired == "" || step.Actions[j].ToolRequired == "null" {
+			step.Actions[j].ToolRequired = text.None
+		}
+		if step.Actions[j].InstructionDetail == "" {
+			step.Actions[j].InstructionDetail = text.NoDetail
+		}
+		if step.Actions[j].TimeMinutes <= 0 {
+			step.Actions[j].TimeMinutes = 1
+		}
+		// 確保 material_required 不為 nil
+		if step.Actions[j].MaterialRequired == nil {
+			step.Actions[j].MaterialRequired = []string{}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package recipe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"recipe-generator/internal/core/ai/service"
+	"recipe-generator/internal/pkg/common"
+	"recipe-generator/internal/pkg/schema"
+
+	"go.uber.org/zap"
+)
+
+// RecipeStreamHandler 串流食譜事件回呼
+type RecipeStreamHandler struct {
+	OnDelta func(delta string) error           // AI 原始增量內容
+	OnStep  func(step common.RecipeStep) error // 已完整解析的食譜步驟
+}
+
+// recipeArrayPattern 匹配食譜步驟陣列的開頭
+var recipeArrayPattern = regexp.MustCompile(`"recipe"\s*:\s*\[`)
+
+// stepParser 從串流中的 JSON 增量解析已完成的食譜步驟
+type stepParser struct {
+	buf        []byte
+	arrayStart int // "recipe" 陣列內容起點，-1 表示尚未找到
+	pos        int // 下一個待掃描的位置
+	depth      int
+	objStart   int
+	inString   bool
+	escaped    bool
+	done       bool
+	emitted    int
+	lang       string // 補充空值使用的語系
+}
+
+// newStepParser 創建步驟解析器
+func newStepParser(lang string) *stepParser {
+	return &stepParser{arrayStart: -1, lang: lang}
+}
+
+// Feed 加入增量內容，返回新完成的步驟
+func (p *stepParser) Feed(delta string) []common.RecipeStep {
+	if p.done {
+		return nil
+	}
+	p.buf = append(p.buf, delta...)
+
+	if p.arrayStart < 0 {
+		loc := recipeArrayPattern.FindIndex(p.buf)
+		if loc == nil {
+			return nil
+		}
+		p.arrayStart = loc[1]
+		p.pos = loc[1]
+	}
+
+	var steps []common.RecipeStep
+	for ; p.pos < len(p.buf); p.pos++ {
+		ch := p.buf[p.pos]
+
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case ch == '\\':
+				p.escaped = true
+			case ch == '"':
+				p.inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			p.inString = true
+		case '{':
+			if p.depth == 0 {
+				p.objStart = p.pos
+			}
+			p.depth++
+		case '}':
+			p.depth--
+			if p.depth == 0 {
+				if step, ok := p.decode(p.buf[p.objStart : p.pos+1]); ok {
+					steps = append(steps, step)
+				}
+			}
+		case ']':
+			if p.depth == 0 {
+				p.done = true
+				return steps
+			}
+		}
+	}
+	return steps
+}
+
+// decode 解析單一步驟並補充空值
+func (p *stepParser) decode(data []byte) (common.RecipeStep, bool) {
+	var step common.RecipeStep
+	if err := json.Unmarshal(data, &step); err != nil {
+		common.LogDebug("串流步驟解析失敗", zap.Error(err))
+		return step, false
+	}
+	normalizeRecipeStep(&step, p.emitted, p.lang)
+	p.emitted++
+	return step, true
+}
+
+// streamRecipe 以串流方式請求 AI，邊接收邊輸出已完成的步驟，最後返回完整驗證後的食譜
+func streamRecipe(ctx context.Context, aiService *service.Service, req *service.Request, handler RecipeStreamHandler) (*common.Recipe, error) {
+	parser := newStepParser(req.Locale)
+
+	resp, err := aiService.ProcessStream(ctx, req, func(delta string) error {
+		if handler.OnDelta != nil {
+			if err := handler.OnDelta(delta); err != nil {
+				return err
+			}
+		}
+		for _, step := range parser.Feed(delta) {
+			if handler.OnStep != nil {
+				if err := handler.OnStep(step); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AI service error: %w", err)
+	}
+
+	if resp == nil || resp.Content == "" {
+		return nil, fmt.Errorf("empty AI response")
+	}
+
+	return parseRecipe(schema.ExtractJSON(resp.Content), req.Locale)
+}
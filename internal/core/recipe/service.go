@@ -6,18 +6,18 @@ import (
 	"strings"
 
 	"recipe-generator/internal/core/ai/cache"
-	"recipe-generator/internal/core/ai/openrouter"
+	"recipe-generator/internal/core/ai/provider"
 	"recipe-generator/internal/core/ai/service"
 )
 
 // Service 食譜服務基礎結構
 type Service struct {
 	aiService    *service.Service
-	cacheManager *cache.CacheManager
+	cacheManager cache.Cache
 }
 
 // NewService 創建新的食譜服務
-func NewService(aiService *service.Service, cacheManager *cache.CacheManager) *Service {
+func NewService(aiService *service.Service, cacheManager cache.Cache) *Service {
 	return &Service{
 		aiService:    aiService,
 		cacheManager: cacheManager,
@@ -25,17 +25,16 @@ func NewService(aiService *service.Service, cacheManager *cache.CacheManager) *S
 }
 
 // handleAIResponse 處理 AI 回應
-func (s *Service) handleAIResponse(resp *openrouter.Response, err error) (string, error) {
+func (s *Service) handleAIResponse(resp *provider.Response, err error) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("AI service error: %w", err)
 	}
 
-	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+	if resp == nil || resp.Content == "" {
 		return "", fmt.Errorf("empty AI response")
 	}
 
-	contentText := resp.Choices[0].Message.Content
-	return strings.TrimSpace(contentText), nil
+	return strings.TrimSpace(resp.Content), nil
 }
 
 // getCacheKey 生成緩存鍵
@@ -48,7 +47,8 @@ func (s *Service) getFromCache(ctx context.Context, key string) (string, error)
 	if s.cacheManager == nil {
 		return "", nil
 	}
-	return s.cacheManager.Get(ctx, "recipe", key)
+	value, _, err := s.cacheManager.Get(ctx, "recipe", key)
+	return value, err
 }
 
 // setToCache 將數據存入緩存
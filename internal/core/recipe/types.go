@@ -36,3 +36,20 @@ type Preference = common.RecipePreferences
 
 // GeneratedRecipe 生成的食譜
 type GeneratedRecipe = common.Recipe
+
+// recipePromptData 食譜生成與推薦模板的參數
+type recipePromptData struct {
+	DishName            string
+	Ingredients         string
+	Equipment           string
+	CookingMethod       string
+	DietaryRestrictions []string
+	ServingSize         string
+	Locale              string
+}
+
+// recognitionPromptData 食物與食材辨識模板的參數
+type recognitionPromptData struct {
+	DescriptionHint string
+	Locale          string
+}
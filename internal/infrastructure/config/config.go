@@ -14,11 +14,20 @@ type Config struct {
 	App         AppConfig        `mapstructure:"app"`
 	Server      ServerConfig     `mapstructure:"server"`
 	OpenRouter  OpenRouterConfig `mapstructure:"openrouter"`
+	Local       LocalConfig      `mapstructure:"local"`
+	Cassette    CassetteConfig   `mapstructure:"cassette"`
 	AI          AIConfig         `mapstructure:"ai"`
+	Routing     RoutingConfig    `mapstructure:"routing"`
 	Cache       CacheConfig      `mapstructure:"cache"`
 	Queue       QueueConfig      `mapstructure:"queue"`
+	Chat        ChatConfig       `mapstructure:"chat"`
+	Jobs        JobsConfig       `mapstructure:"jobs"`
+	Prompt      PromptConfig     `mapstructure:"prompt"`
 	RateLimit   RateLimitConfig  `mapstructure:"rate_limit"`
 	Image       ImageConfig      `mapstructure:"image"`
+	Batch       BatchConfig      `mapstructure:"batch"`
+	Readiness   ReadinessConfig  `mapstructure:"readiness"`
+	Admin       AdminConfig      `mapstructure:"admin"`
 	DedupWindow time.Duration    `mapstructure:"dedup_window"`
 	LogLevel    string           `mapstructure:"log_level"`
 }
@@ -49,19 +58,127 @@ type OpenRouterConfig struct {
 	Timeout   time.Duration `mapstructure:"timeout"`
 }
 
+// LocalConfig 自架 OpenAI 相容端點配置（Ollama / llama.cpp / vLLM）
+type LocalConfig struct {
+	BaseURL  string        `mapstructure:"base_url"`
+	Model    string        `mapstructure:"model"`
+	APIKey   string        `mapstructure:"api_key"`
+	AuthType string        `mapstructure:"auth_type"`
+	Headers  string        `mapstructure:"headers"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// HeaderMap 解析自訂請求頭，格式為 "Key1=Value1,Key2=Value2"
+func (c LocalConfig) HeaderMap() map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(c.Headers, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// CassetteConfig 錄製/回放配置（AI_PROVIDER=record 或 replay）
+type CassetteConfig struct {
+	Dir      string `mapstructure:"dir"`
+	Upstream string `mapstructure:"upstream"`
+}
+
 // AIConfig AI 配置
 type AIConfig struct {
-	EnableCache  bool `mapstructure:"enable_cache"`
-	MaxQueueSize int  `mapstructure:"max_queue_size"`
-	Workers      int  `mapstructure:"workers"`
+	Provider         string        `mapstructure:"provider"`
+	EnableCache      bool          `mapstructure:"enable_cache"`
+	MaxQueueSize     int           `mapstructure:"max_queue_size"`
+	Workers          int           `mapstructure:"workers"`
+	FallbackModels   []string      `mapstructure:"fallback_models"`
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+	// SchemaModels 支援 response_format json_schema 的模型前綴，"*" 表示全部
+	SchemaModels []string `mapstructure:"schema_models"`
+	// SchemaRepairAttempts 回應未通過結構驗證時，回饋錯誤請模型修正的最大次數
+	SchemaRepairAttempts int `mapstructure:"schema_repair_attempts"`
+	// Pricing 模型單價（美元 / 每百萬 tokens），格式 "model=prompt:completion,..."，
+	// 用於估算提供者未回報費用的呼叫
+	Pricing string `mapstructure:"pricing"`
+	// MaxRetries 同一模型遇到 408/429/5xx/連線中斷時的最大重試次數
+	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBaseDelay / RetryMaxDelay 重試的指數退避基本等待時間與單次上限
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	RetryMaxDelay  time.Duration `mapstructure:"retry_max_delay"`
+	// ToolModels 支援 tools 工具呼叫的模型前綴，"*" 表示全部
+	ToolModels []string `mapstructure:"tool_models"`
+	// MaxToolDepth 單次請求最多執行幾輪工具呼叫，超過後要求模型直接回答
+	MaxToolDepth int `mapstructure:"max_tool_depth"`
+	// Coalesce 合併進行中的相同請求，後到的呼叫者等待同一個上游呼叫的結果
+	Coalesce bool `mapstructure:"coalesce"`
+}
+
+// RoutingConfig 各任務的模型路由
+type RoutingConfig struct {
+	FoodRecognition       TaskRoute `mapstructure:"food_recognition"`
+	IngredientRecognition TaskRoute `mapstructure:"ingredient_recognition"`
+	RecipeGeneration      TaskRoute `mapstructure:"recipe_generation"`
+	RecipeSuggestion      TaskRoute `mapstructure:"recipe_suggestion"`
+	Chat                  TaskRoute `mapstructure:"chat"`
+}
+
+// TaskRoute 單一任務的路由設定
+type TaskRoute struct {
+	Models      []string `mapstructure:"models"`      // 依序嘗試的模型，留空則使用預設降級鏈
+	MaxTokens   int      `mapstructure:"max_tokens"`  // 回應 token 上限，0 使用 OPENROUTER_MAX_TOKENS
+	Temperature *float64 `mapstructure:"temperature"` // 取樣溫度，未設定時由模型決定
+	TopP        *float64 `mapstructure:"top_p"`       // nucleus sampling，未設定時由模型決定
+}
+
+// Routes 以任務名稱列出所有路由
+func (r RoutingConfig) Routes() map[string]TaskRoute {
+	return map[string]TaskRoute{
+		"food_recognition":       r.FoodRecognition,
+		"ingredient_recognition": r.IngredientRecognition,
+		"recipe_generation":      r.RecipeGeneration,
+		"recipe_suggestion":      r.RecipeSuggestion,
+		"chat":                   r.Chat,
+	}
+}
+
+// Route 獲取任務的路由設定，未知任務返回空設定
+func (r RoutingConfig) Route(task string) TaskRoute {
+	return r.Routes()[task]
 }
 
 // CacheConfig 緩存配置
 type CacheConfig struct {
 	Enabled         bool          `mapstructure:"enabled"`
+	Backend         string        `mapstructure:"backend"` // memory、redis、tiered 或 disk
 	MaxSize         int           `mapstructure:"max_size"`
 	TTL             time.Duration `mapstructure:"ttl"`
+	StaleTTL        time.Duration `mapstructure:"stale_ttl"`       // 超過 TTL 後仍回傳舊值並於背景更新的時間，0 表示停用
+	RefreshTimeout  time.Duration `mapstructure:"refresh_timeout"` // 背景更新快取的執行時間上限
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	L1MaxSize       int           `mapstructure:"l1_max_size"`      // 分層緩存 L1 的條目上限
+	L1TTL           time.Duration `mapstructure:"l1_ttl"`           // 分層緩存 L1 的存活時間，限制遺失失效通知時的過期時間
+	ImageKey        string        `mapstructure:"image_key"`        // 圖片緩存鍵：sha256（完全相同）或 dhash（感知哈希）
+	ImageDistance   int           `mapstructure:"image_distance"`   // dhash 模式視為同一張圖片的漢明距離上限（0-64）
+	ImageIndexSize  int           `mapstructure:"image_index_size"` // dhash 模式索引保留的圖片哈希數量上限
+	Redis           RedisConfig   `mapstructure:"redis"`
+	Disk            DiskConfig    `mapstructure:"disk"`
+}
+
+// DiskConfig 磁碟緩存後端設定
+type DiskConfig struct {
+	Path     string `mapstructure:"path"`      // bbolt 檔案路徑
+	MaxBytes int64  `mapstructure:"max_bytes"` // 所有條目（鍵 + 值）的大小上限
+}
+
+// RedisConfig Redis 緩存後端設定
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	Prefix   string `mapstructure:"prefix"` // 所有緩存鍵的前綴，用於與其他應用共用同一個 Redis
 }
 
 // QueueConfig 請求隊列設定
@@ -70,6 +187,31 @@ type QueueConfig struct {
 	MaxSize int `mapstructure:"max_size"`
 }
 
+// ChatConfig 烹飪助理對話設定
+type ChatConfig struct {
+	SessionTTL  time.Duration `mapstructure:"session_ttl"`  // 閒置多久後對話過期
+	MaxSessions int           `mapstructure:"max_sessions"` // 同時保留的對話上限
+	MaxHistory  int           `mapstructure:"max_history"`  // 保留的訊息數上限，超過時摘要較早的訊息
+	TokenBudget int           `mapstructure:"token_budget"` // 對話紀錄的估計 token 上限，超過時摘要較早的訊息
+	KeepRecent  int           `mapstructure:"keep_recent"`  // 摘要時保留原文的最近訊息數
+}
+
+// JobsConfig 非同步工作設定
+type JobsConfig struct {
+	Retention      time.Duration `mapstructure:"retention"`       // 工作完成後保留結果的時間
+	Timeout        time.Duration `mapstructure:"timeout"`         // 單一工作的執行時間上限
+	MaxJobs        int           `mapstructure:"max_jobs"`        // 同時保留的工作上限
+	WebhookSecret  string        `mapstructure:"webhook_secret"`  // 回呼簽章金鑰，未設定時不接受回呼網址
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"` // 單次回呼請求的超時
+	WebhookRetries int           `mapstructure:"webhook_retries"` // 回呼失敗後的重試次數
+}
+
+// PromptConfig prompt 模板設定
+type PromptConfig struct {
+	Dir      string `mapstructure:"dir"`      // 覆蓋內嵌模板的目錄，不存在時僅使用內嵌模板
+	Versions string `mapstructure:"versions"` // 指定模板版本，格式為 name=2,name2=1，未指定者使用最新版本
+}
+
 // RateLimitConfig 速率限制配置
 type RateLimitConfig struct {
 	Enabled  bool          `mapstructure:"enabled"`
@@ -82,6 +224,24 @@ type ImageConfig struct {
 	MaxSizeBytes int64 `mapstructure:"max_size_bytes"`
 }
 
+// BatchConfig 批次圖片辨識設定
+type BatchConfig struct {
+	MaxImages   int `mapstructure:"max_images"`  // 單次請求的圖片數上限
+	Concurrency int `mapstructure:"concurrency"` // 單次請求同時辨識的圖片數
+}
+
+// ReadinessConfig 就緒檢查設定
+type ReadinessConfig struct {
+	ProbeTTL       time.Duration `mapstructure:"probe_ttl"`       // 上游探測結果的快取時間
+	ProbeTimeout   time.Duration `mapstructure:"probe_timeout"`   // 單次上游探測的超時
+	QueueThreshold float64       `mapstructure:"queue_threshold"` // 隊列長度達上限的比例（0-1）時視為未就緒
+}
+
+// AdminConfig 管理 API 設定
+type AdminConfig struct {
+	Token string `mapstructure:"token"` // 管理 API 的 Bearer token，未設定時不開放管理 API
+}
+
 // LoadConfig 載入設定
 func LoadConfig() (*Config, error) {
 	// 加載 .env 文件
@@ -101,10 +261,76 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("openrouter.api_key", "OPENROUTER_API_KEY")
 	viper.BindEnv("openrouter.model", "OPENROUTER_MODEL")
 	viper.BindEnv("openrouter.max_tokens", "MODEL_MAX_TOKENS")
+	viper.BindEnv("ai.provider", "AI_PROVIDER")
+	viper.BindEnv("local.base_url", "LOCAL_BASE_URL")
+	viper.BindEnv("local.model", "LOCAL_MODEL")
+	viper.BindEnv("local.api_key", "LOCAL_API_KEY")
+	viper.BindEnv("local.auth_type", "LOCAL_AUTH_TYPE")
+	viper.BindEnv("local.headers", "LOCAL_HEADERS")
+	viper.BindEnv("local.timeout", "LOCAL_TIMEOUT")
+	viper.BindEnv("ai.fallback_models", "AI_FALLBACK_MODELS")
+	viper.BindEnv("ai.breaker_threshold", "AI_BREAKER_THRESHOLD")
+	viper.BindEnv("ai.breaker_cooldown", "AI_BREAKER_COOLDOWN")
+	viper.BindEnv("ai.schema_models", "AI_SCHEMA_MODELS")
+	viper.BindEnv("ai.schema_repair_attempts", "AI_SCHEMA_REPAIR_ATTEMPTS")
+	viper.BindEnv("ai.pricing", "AI_PRICING")
+	viper.BindEnv("ai.max_retries", "AI_MAX_RETRIES")
+	viper.BindEnv("ai.retry_base_delay", "AI_RETRY_BASE_DELAY")
+	viper.BindEnv("ai.retry_max_delay", "AI_RETRY_MAX_DELAY")
+	viper.BindEnv("ai.tool_models", "AI_TOOL_MODELS")
+	viper.BindEnv("ai.max_tool_depth", "AI_MAX_TOOL_DEPTH")
+	viper.BindEnv("ai.coalesce", "AI_COALESCE")
+	for _, task := range []string{"food_recognition", "ingredient_recognition", "recipe_generation", "recipe_suggestion", "chat"} {
+		env := "ROUTE_" + strings.ToUpper(task)
+		viper.BindEnv("routing."+task+".models", env+"_MODELS")
+		viper.BindEnv("routing."+task+".max_tokens", env+"_MAX_TOKENS")
+		viper.BindEnv("routing."+task+".temperature", env+"_TEMPERATURE")
+		viper.BindEnv("routing."+task+".top_p", env+"_TOP_P")
+	}
+	viper.BindEnv("cassette.dir", "CASSETTE_DIR")
+	viper.BindEnv("cassette.upstream", "CASSETTE_UPSTREAM")
+	viper.BindEnv("chat.session_ttl", "CHAT_SESSION_TTL")
+	viper.BindEnv("chat.max_sessions", "CHAT_MAX_SESSIONS")
+	viper.BindEnv("chat.max_history", "CHAT_MAX_HISTORY")
+	viper.BindEnv("chat.token_budget", "CHAT_TOKEN_BUDGET")
+	viper.BindEnv("chat.keep_recent", "CHAT_KEEP_RECENT")
+	viper.BindEnv("jobs.retention", "JOBS_RETENTION")
+	viper.BindEnv("jobs.timeout", "JOBS_TIMEOUT")
+	viper.BindEnv("jobs.max_jobs", "JOBS_MAX_JOBS")
+	viper.BindEnv("jobs.webhook_secret", "JOBS_WEBHOOK_SECRET")
+	viper.BindEnv("jobs.webhook_timeout", "JOBS_WEBHOOK_TIMEOUT")
+	viper.BindEnv("jobs.webhook_retries", "JOBS_WEBHOOK_RETRIES")
+	viper.BindEnv("prompt.dir", "PROMPT_DIR")
+	viper.BindEnv("prompt.versions", "PROMPT_VERSIONS")
 	viper.BindEnv("cache.enabled", "CACHE_ENABLED")
+	viper.BindEnv("cache.backend", "CACHE_BACKEND")
+	viper.BindEnv("cache.max_size", "CACHE_MAX_SIZE")
+	viper.BindEnv("cache.ttl", "CACHE_TTL")
+	viper.BindEnv("cache.stale_ttl", "CACHE_STALE_TTL")
+	viper.BindEnv("cache.refresh_timeout", "CACHE_REFRESH_TIMEOUT")
+	viper.BindEnv("cache.cleanup_interval", "CACHE_CLEANUP_INTERVAL")
+	viper.BindEnv("cache.l1_max_size", "CACHE_L1_MAX_SIZE")
+	viper.BindEnv("cache.l1_ttl", "CACHE_L1_TTL")
+	viper.BindEnv("cache.image_key", "CACHE_IMAGE_KEY")
+	viper.BindEnv("cache.image_distance", "CACHE_IMAGE_DISTANCE")
+	viper.BindEnv("cache.image_index_size", "CACHE_IMAGE_INDEX_SIZE")
+	viper.BindEnv("cache.disk.path", "CACHE_DISK_PATH")
+	viper.BindEnv("cache.disk.max_bytes", "CACHE_DISK_MAX_BYTES")
+	viper.BindEnv("cache.redis.addr", "CACHE_REDIS_ADDR")
+	viper.BindEnv("cache.redis.password", "CACHE_REDIS_PASSWORD")
+	viper.BindEnv("cache.redis.db", "CACHE_REDIS_DB")
+	viper.BindEnv("cache.redis.prefix", "CACHE_REDIS_PREFIX")
 	viper.BindEnv("rate_limit.enabled", "RATE_LIMIT_ENABLED")
 	viper.BindEnv("rate_limit.requests", "RATE_LIMIT_REQUESTS")
 	viper.BindEnv("rate_limit.window", "RATE_LIMIT_WINDOW")
+	viper.BindEnv("queue.workers", "QUEUE_WORKERS")
+	viper.BindEnv("queue.max_size", "QUEUE_MAX_SIZE")
+	viper.BindEnv("batch.max_images", "BATCH_MAX_IMAGES")
+	viper.BindEnv("batch.concurrency", "BATCH_CONCURRENCY")
+	viper.BindEnv("readiness.probe_ttl", "READINESS_PROBE_TTL")
+	viper.BindEnv("readiness.probe_timeout", "READINESS_PROBE_TIMEOUT")
+	viper.BindEnv("readiness.queue_threshold", "READINESS_QUEUE_THRESHOLD")
+	viper.BindEnv("admin.token", "ADMIN_TOKEN")
 	viper.BindEnv("dedup_window", "DEDUP_WINDOW")
 	viper.BindEnv("log_level", "LOG_LEVEL")
 
@@ -166,21 +392,76 @@ func setDefaults() {
 	viper.SetDefault("openrouter.max_tokens", 1000)
 	viper.SetDefault("openrouter.timeout", "60s")
 
+	// 自架模型設定
+	viper.SetDefault("local.base_url", "http://localhost:11434/v1")
+	viper.SetDefault("local.auth_type", "none")
+	viper.SetDefault("local.timeout", "120s")
+
+	// 錄製/回放設定
+	viper.SetDefault("cassette.dir", "testdata/cassettes")
+	viper.SetDefault("cassette.upstream", "openrouter")
+
 	// AI 設定
+	viper.SetDefault("ai.provider", "openrouter")
 	viper.SetDefault("ai.enable_cache", true)
 	viper.SetDefault("ai.max_queue_size", 100)
 	viper.SetDefault("ai.workers", 5)
+	viper.SetDefault("ai.fallback_models", []string{})
+	viper.SetDefault("ai.breaker_threshold", 3)
+	viper.SetDefault("ai.breaker_cooldown", "30s")
+	viper.SetDefault("ai.schema_models", []string{"openai/", "google/gemini-"})
+	viper.SetDefault("ai.schema_repair_attempts", 1)
+	viper.SetDefault("ai.pricing", "")
+	viper.SetDefault("ai.max_retries", 2)
+	viper.SetDefault("ai.retry_base_delay", "500ms")
+	viper.SetDefault("ai.retry_max_delay", "10s")
+	viper.SetDefault("ai.tool_models", []string{"openai/", "google/gemini-", "anthropic/"})
+	viper.SetDefault("ai.max_tool_depth", 3)
+	viper.SetDefault("ai.coalesce", true)
 
 	// 快取設定
 	viper.SetDefault("cache.enabled", true)
 	viper.SetDefault("cache.max_size", 1000)
 	viper.SetDefault("cache.ttl", "24h")
+	viper.SetDefault("cache.stale_ttl", "24h")
+	viper.SetDefault("cache.refresh_timeout", "2m")
 	viper.SetDefault("cache.cleanup_interval", "10m")
+	viper.SetDefault("cache.backend", "memory")
+	viper.SetDefault("cache.l1_max_size", 200)
+	viper.SetDefault("cache.l1_ttl", "5m")
+	viper.SetDefault("cache.image_key", "sha256")
+	viper.SetDefault("cache.image_distance", 6)
+	viper.SetDefault("cache.image_index_size", 10000)
+	viper.SetDefault("cache.disk.path", "data/cache.db")
+	viper.SetDefault("cache.disk.max_bytes", 256<<20) // 256MB
+	viper.SetDefault("cache.redis.addr", "localhost:6379")
+	viper.SetDefault("cache.redis.password", "")
+	viper.SetDefault("cache.redis.db", 0)
+	viper.SetDefault("cache.redis.prefix", "recipe:cache:")
 
 	// 隊列設定
 	viper.SetDefault("queue.workers", 5)
 	viper.SetDefault("queue.max_size", 100)
 
+	// 對話設定
+	viper.SetDefault("chat.session_ttl", "30m")
+	viper.SetDefault("chat.max_sessions", 1000)
+	viper.SetDefault("chat.max_history", 20)
+	viper.SetDefault("chat.token_budget", 3000)
+	viper.SetDefault("chat.keep_recent", 6)
+
+	// 非同步工作設定
+	viper.SetDefault("jobs.retention", "1h")
+	viper.SetDefault("jobs.timeout", "10m")
+	viper.SetDefault("jobs.max_jobs", 1000)
+	viper.SetDefault("jobs.webhook_secret", "")
+	viper.SetDefault("jobs.webhook_timeout", "10s")
+	viper.SetDefault("jobs.webhook_retries", 3)
+
+	// Prompt 模板設定
+	viper.SetDefault("prompt.dir", "prompts")
+	viper.SetDefault("prompt.versions", "")
+
 	// 限流設定
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.requests", 100)
@@ -189,6 +470,13 @@ func setDefaults() {
 	// 圖片設定
 	viper.SetDefault("image.max_size_bytes", 10*1024*1024) // 10MB
 
+	// 批次辨識設定
+	viper.SetDefault("batch.max_images", 10)
+	viper.SetDefault("batch.concurrency", 3)
+	viper.SetDefault("readiness.probe_ttl", "30s")
+	viper.SetDefault("readiness.probe_timeout", "5s")
+	viper.SetDefault("readiness.queue_threshold", 0.9)
+
 	// 新增 dedup window 預設
 	viper.SetDefault("dedup_window", "1s")
 }
@@ -208,9 +496,72 @@ func validateConfig(config *Config) error {
 		if config.Cache.TTL <= 0 {
 			return fmt.Errorf("invalid cache ttl")
 		}
+		if config.Cache.StaleTTL < 0 {
+			return fmt.Errorf("invalid cache stale ttl")
+		}
+		if config.Cache.RefreshTimeout <= 0 {
+			return fmt.Errorf("invalid cache refresh timeout")
+		}
 		if config.Cache.CleanupInterval <= 0 {
 			return fmt.Errorf("invalid cache cleanup interval")
 		}
+		switch config.Cache.ImageKey {
+		case "sha256":
+		case "dhash":
+			if config.Cache.ImageDistance < 0 || config.Cache.ImageDistance > 64 || config.Cache.ImageIndexSize <= 1 {
+				return fmt.Errorf("invalid cache image distance or index size")
+			}
+			// 感知哈希索引只存於程序內，跨副本共用或重啟後保留的快取無法以相同的代表哈希命中
+			if config.Cache.Backend != "memory" {
+				return fmt.Errorf("cache image key dhash requires the memory cache backend")
+			}
+		default:
+			return fmt.Errorf("cache image key must be sha256 or dhash")
+		}
+		switch config.Cache.Backend {
+		case "memory":
+		case "redis", "tiered":
+			if config.Cache.Redis.Addr == "" || config.Cache.Redis.DB < 0 {
+				return fmt.Errorf("invalid cache redis addr or db")
+			}
+			if config.Cache.Backend == "tiered" && (config.Cache.L1MaxSize <= 0 || config.Cache.L1TTL <= 0) {
+				return fmt.Errorf("invalid cache l1 max size or ttl")
+			}
+		case "disk":
+			if config.Cache.Disk.Path == "" || config.Cache.Disk.MaxBytes <= 0 {
+				return fmt.Errorf("invalid cache disk path or max bytes")
+			}
+		default:
+			return fmt.Errorf("cache backend must be memory, redis, tiered or disk")
+		}
+	}
+
+	// 驗證自架模型設定
+	if strings.EqualFold(config.AI.Provider, "local") {
+		if config.Local.BaseURL == "" {
+			return fmt.Errorf("local base url is required")
+		}
+		if config.Local.Model == "" {
+			return fmt.Errorf("local model is required")
+		}
+	}
+
+	// 驗證任務路由設定
+	for task, route := range config.Routing.Routes() {
+		if route.MaxTokens < 0 {
+			return fmt.Errorf("invalid %s route max tokens", task)
+		}
+		if route.Temperature != nil && (*route.Temperature < 0 || *route.Temperature > 2) {
+			return fmt.Errorf("%s route temperature must be between 0 and 2", task)
+		}
+		if route.TopP != nil && (*route.TopP <= 0 || *route.TopP > 1) {
+			return fmt.Errorf("%s route top_p must be in (0, 1]", task)
+		}
+	}
+
+	// 驗證工具呼叫設定
+	if config.AI.MaxToolDepth <= 0 {
+		return fmt.Errorf("invalid ai max tool depth")
 	}
 
 	// 驗證隊列設定
@@ -221,5 +572,53 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("invalid queue max size")
 	}
 
+	// 驗證限流設定
+	if config.RateLimit.Enabled && (config.RateLimit.Requests <= 0 || config.RateLimit.Window <= 0) {
+		return fmt.Errorf("invalid rate limit: requests and window must be positive")
+	}
+
+	// 驗證對話設定
+	if config.Chat.SessionTTL <= 0 {
+		return fmt.Errorf("invalid chat session ttl")
+	}
+	if config.Chat.MaxSessions <= 0 {
+		return fmt.Errorf("invalid chat max sessions")
+	}
+	if config.Chat.KeepRecent < 0 || config.Chat.KeepRecent >= config.Chat.MaxHistory {
+		return fmt.Errorf("chat keep recent must be between 0 and max history")
+	}
+
+	// 驗證批次辨識設定
+	if config.Batch.MaxImages <= 0 || config.Batch.Concurrency <= 0 {
+		return fmt.Errorf("invalid batch max images or concurrency")
+	}
+
+	// 驗證就緒檢查設定
+	if config.Readiness.ProbeTTL < 0 || config.Readiness.ProbeTimeout <= 0 {
+		return fmt.Errorf("invalid readiness probe ttl or timeout")
+	}
+	if config.Readiness.QueueThreshold <= 0 || config.Readiness.QueueThreshold > 1 {
+		return fmt.Errorf("readiness queue threshold must be between 0 and 1")
+	}
+
+	// 驗證管理 API 設定，過短的 token 容易被猜中
+	if config.Admin.Token != "" && len(config.Admin.Token) < 16 {
+		return fmt.Errorf("admin token must be at least 16 characters")
+	}
+
+	// 驗證非同步工作設定
+	if config.Jobs.Retention <= 0 {
+		return fmt.Errorf("invalid jobs retention")
+	}
+	if config.Jobs.Timeout <= 0 {
+		return fmt.Errorf("invalid jobs timeout")
+	}
+	if config.Jobs.MaxJobs <= 0 {
+		return fmt.Errorf("invalid jobs max jobs")
+	}
+	if config.Jobs.WebhookTimeout <= 0 || config.Jobs.WebhookRetries < 0 {
+		return fmt.Errorf("invalid jobs webhook timeout or retries")
+	}
+
 	return nil
 }
@@ -215,17 +215,17 @@ func LogCacheMiss(cacheType, key string) {
 }
 
 // LogAICall 記錄 AI 調用
-func LogAICall(prompt string, duration time.Duration, err error, requestID string) {
+func LogAICall(prompt string, duration time.Duration, err error, requestID string, fields ...zap.Field) {
+	fields = append([]zap.Field{
+		zap.Duration("耗時", duration),
+		zap.Int("prompt_length", len(prompt)),
+		zap.String("request_id", requestID),
+	}, fields...)
 	if err != nil {
-		LogError("AI 請求失敗",
-			zap.Error(err),
-			zap.Duration("耗時", duration),
-		)
+		LogError("AI 請求失敗", append(fields, zap.Error(err))...)
 		return
 	}
-	LogInfo("AI 請求成功",
-		zap.Duration("耗時", duration),
-	)
+	LogInfo("AI 請求成功", fields...)
 }
 
 // LogImageProcessing 記錄圖片處理相關的日誌
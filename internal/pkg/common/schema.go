@@ -0,0 +1,78 @@
+package common
+
+import "recipe-generator/internal/pkg/schema"
+
+// 各 AI 回應結構的 JSON Schema，對應 types.go 宣告的結果型別
+// 會以 response_format 傳給支援結構化輸出的模型，並用於驗證回應與修復重試
+
+var ingredientSchema = schema.Object(
+	schema.Prop("name", schema.String("食材名稱")),
+	schema.Prop("type", schema.String("食材類型")),
+	schema.Prop("amount", schema.String("數量")),
+	schema.Prop("unit", schema.String("單位")),
+	schema.Prop("preparation", schema.String("處理方式")),
+)
+
+var equipmentSchema = schema.Object(
+	schema.Prop("name", schema.String("設備名稱")),
+	schema.Prop("type", schema.String("設備類型")),
+	schema.Prop("size", schema.String("尺寸").OrNull()),
+	schema.Prop("material", schema.String("材質").OrNull()),
+	schema.Prop("power_source", schema.String("能源類型").OrNull()),
+)
+
+// RecipeSchema common.Recipe 的 JSON Schema
+var RecipeSchema = &schema.Definition{
+	Name: "recipe",
+	Schema: schema.Object(
+		schema.Prop("dish_name", schema.String("菜名")),
+		schema.Prop("dish_description", schema.String("描述")),
+		schema.Prop("ingredients", schema.Array(ingredientSchema)),
+		schema.Prop("equipment", schema.Array(equipmentSchema)),
+		schema.Prop("recipe", schema.Array(schema.Object(
+			schema.Prop("step_number", schema.Integer("步驟編號")),
+			schema.Prop("title", schema.String("步驟標題")),
+			schema.Prop("description", schema.String("步驟描述")),
+			schema.Prop("actions", schema.Array(schema.Object(
+				schema.Prop("action", schema.String("動作")),
+				schema.Prop("tool_required", schema.String("工具").OrNull()),
+				schema.Prop("material_required", schema.Array(schema.String("材料"))),
+				schema.Prop("time_minutes", schema.Integer("時間（秒，整數）")),
+				schema.Prop("instruction_detail", schema.String("細節")),
+			))),
+			schema.Prop("estimated_total_time", schema.String("預估總時間")),
+			schema.Prop("temperature", schema.String("火侯").OrNull()),
+			schema.Prop("warnings", schema.String("警告事項，沒有則為 null").OrNull()),
+			schema.Prop("notes", schema.String("備註").OrNull()),
+		)).AtLeast(1)),
+	),
+}
+
+// IngredientRecognitionSchema common.IngredientRecognitionResult 的 JSON Schema
+var IngredientRecognitionSchema = &schema.Definition{
+	Name: "ingredient_recognition",
+	Schema: schema.Object(
+		schema.Prop("ingredients", schema.Array(ingredientSchema)),
+		schema.Prop("equipment", schema.Array(equipmentSchema)),
+		schema.Prop("summary", schema.String("辨識內容摘要")),
+	),
+}
+
+// FoodRecognitionSchema common.FoodRecognitionResult 的 JSON Schema
+var FoodRecognitionSchema = &schema.Definition{
+	Name: "food_recognition",
+	Schema: schema.Object(
+		schema.Prop("recognized_foods", schema.Array(schema.Object(
+			schema.Prop("name", schema.String("食物名稱")),
+			schema.Prop("description", schema.String("此食物的特徵與可能料理方式說明")),
+			schema.Prop("possible_ingredients", schema.Array(schema.Object(
+				schema.Prop("name", schema.String("食材名稱")),
+				schema.Prop("type", schema.String("食材類型")),
+			))),
+			schema.Prop("possible_equipment", schema.Array(schema.Object(
+				schema.Prop("name", schema.String("設備名稱")),
+				schema.Prop("type", schema.String("設備類型")),
+			))),
+		))),
+	),
+}
@@ -77,6 +77,7 @@ type RecipeByIngredientsRequest struct {
 		DietaryRestrictions []string `json:"dietary_restrictions"`
 		ServingSize         string   `json:"serving_size"`
 	} `json:"preference"`
+	Locale string `json:"locale,omitempty"` // 輸出語系
 }
 
 // FormatIngredients 格式化食材列表
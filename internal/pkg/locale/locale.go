@@ -0,0 +1,118 @@
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支援的輸出語系
+const (
+	ZhTW = "zh-TW"
+	En   = "en"
+	Ja   = "ja"
+)
+
+// Default 未指定或不支援時使用的語系
+const Default = ZhTW
+
+// languages 各語系在 prompt 中的語言名稱
+var languages = map[string]string{
+	ZhTW: "繁體中文",
+	En:   "英文（English）",
+	Ja:   "日文（日本語）",
+}
+
+// aliases 非完全相符時的對應：依主要語言代碼比對
+// 中文僅支援繁體，zh、zh-Hant、zh-HK 等皆對應至 zh-TW
+var aliases = map[string]string{
+	"zh": ZhTW,
+	"en": En,
+	"ja": Ja,
+}
+
+// Supported 獲取支援的語系
+func Supported() []string {
+	return []string{ZhTW, En, Ja}
+}
+
+// IsSupported 檢查語系是否支援（需完全相符）
+func IsSupported(tag string) bool {
+	_, ok := languages[tag]
+	return ok
+}
+
+// Language 獲取語系在 prompt 中的語言名稱，不支援時返回預設語系的名稱
+func Language(tag string) string {
+	if name, ok := languages[tag]; ok {
+		return name
+	}
+	return languages[Default]
+}
+
+// Match 將語言標籤對應至支援的語系：先比對完整標籤（不分大小寫），
+// 再比對主要語言代碼；無法對應時 ok 為 false
+func Match(tag string) (string, bool) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" || tag == "*" {
+		return "", false
+	}
+	for _, supported := range Supported() {
+		if strings.EqualFold(tag, supported) {
+			return supported, true
+		}
+	}
+	primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	supported, ok := aliases[primary]
+	return supported, ok
+}
+
+// Resolve 決定輸出語系：明確指定的 locale 優先，其次依 Accept-Language
+// 的權重順序選擇第一個可對應的語系，皆無法對應時使用預設語系
+func Resolve(explicit, acceptLanguage string) string {
+	if tag, ok := Match(explicit); ok {
+		return tag
+	}
+	for _, candidate := range parseAcceptLanguage(acceptLanguage) {
+		if tag, ok := Match(candidate); ok {
+			return tag
+		}
+	}
+	return Default
+}
+
+// parseAcceptLanguage 解析 Accept-Language，依 q 值由高至低排序，q=0 的語言會被略過
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag, q})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
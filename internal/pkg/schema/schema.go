@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// JSON Schema 型別
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Schema JSON Schema 的精簡子集，足以描述 AI 回應結構並用於 response_format
+// 物件的所有屬性皆為必填且不允許額外屬性（符合 strict 結構化輸出的要求），
+// 可省略的欄位以 Nullable 表示
+type Schema struct {
+	Type        string
+	Nullable    bool
+	Description string
+	Properties  map[string]*Schema
+	Order       []string // 屬性宣告順序，用於 required 與驗證訊息
+	Items       *Schema
+	MinItems    int
+}
+
+// Definition 具名的 JSON Schema
+type Definition struct {
+	Name   string
+	Schema *Schema
+}
+
+// Field 物件屬性
+type Field struct {
+	Name   string
+	Schema *Schema
+}
+
+// String 字串型別
+func String(description string) *Schema {
+	return &Schema{Type: TypeString, Description: description}
+}
+
+// Integer 整數型別
+func Integer(description string) *Schema {
+	return &Schema{Type: TypeInteger, Description: description}
+}
+
+// Number 數值型別
+func Number(description string) *Schema {
+	return &Schema{Type: TypeNumber, Description: description}
+}
+
+// Array 陣列型別
+func Array(items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Items: items}
+}
+
+// Object 物件型別，屬性依傳入順序宣告
+func Object(fields ...Field) *Schema {
+	s := &Schema{
+		Type:       TypeObject,
+		Properties: make(map[string]*Schema, len(fields)),
+		Order:      make([]string, 0, len(fields)), // 無屬性時輸出 required: []
+	}
+	for _, f := range fields {
+		s.Properties[f.Name] = f.Schema
+		s.Order = append(s.Order, f.Name)
+	}
+	return s
+}
+
+// Prop 創建物件屬性
+func Prop(name string, schema *Schema) Field {
+	return Field{Name: name, Schema: schema}
+}
+
+// OrNull 允許值為 null
+func (s *Schema) OrNull() *Schema {
+	c := *s
+	c.Nullable = true
+	return &c
+}
+
+// AtLeast 設定陣列最少元素數量
+func (s *Schema) AtLeast(n int) *Schema {
+	c := *s
+	c.MinItems = n
+	return &c
+}
+
+// MarshalJSON 輸出標準 JSON Schema
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{})
+	if s.Nullable {
+		out["type"] = []string{s.Type, "null"}
+	} else {
+		out["type"] = s.Type
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	switch s.Type {
+	case TypeObject:
+		out["properties"] = s.Properties
+		out["required"] = s.Order
+		out["additionalProperties"] = false
+	case TypeArray:
+		out["items"] = s.Items
+		if s.MinItems > 0 {
+			out["minItems"] = s.MinItems
+		}
+	}
+	return json.Marshal(out)
+}
+
+// ExtractJSON 去除 markdown 等多餘內容，擷取第一個 { 到最後一個 } 之間的內容
+func ExtractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start != -1 && end != -1 && end > start {
+		content = content[start : end+1]
+	}
+	return content
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxProblems 單次驗證最多回報的問題數量，避免修復提示過長
+const maxProblems = 20
+
+// ValidationError 回應未通過 JSON Schema 驗證
+type ValidationError struct {
+	Schema   string
+	Problems []string
+}
+
+// Error 實作 error 介面
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("response does not match schema %s: %s", e.Schema, strings.Join(e.Problems, "; "))
+}
+
+// Validate 驗證內容（會先擷取 JSON 物件）是否符合定義，通過時返回 nil
+// 未宣告的額外屬性會被忽略，以免模型多給的欄位造成不必要的修復
+func (d *Definition) Validate(content string) error {
+	dec := json.NewDecoder(bytes.NewReader([]byte(ExtractJSON(content))))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return &ValidationError{Schema: d.Name, Problems: []string{fmt.Sprintf("$: invalid JSON: %v", err)}}
+	}
+
+	var problems []string
+	d.Schema.validate("$", value, &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > maxProblems {
+		problems = append(problems[:maxProblems], fmt.Sprintf("... and %d more", len(problems)-maxProblems))
+	}
+	return &ValidationError{Schema: d.Name, Problems: problems}
+}
+
+// validate 遞迴驗證值並收集問題
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	if value == nil {
+		if !s.Nullable {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got null", path, s.Type))
+		}
+		return
+	}
+
+	switch s.Type {
+	case TypeObject:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected object, got %s", path, typeOf(value)))
+			return
+		}
+		for _, name := range s.fieldNames() {
+			child, exists := obj[name]
+			if !exists {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: required field is missing", path, name))
+				continue
+			}
+			s.Properties[name].validate(path+"."+name, child, problems)
+		}
+	case TypeArray:
+		arr, ok := value.([]interface{})
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected array, got %s", path, typeOf(value)))
+			return
+		}
+		if len(arr) < s.MinItems {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at least %d items, got %d", path, s.MinItems, len(arr)))
+		}
+		for i, item := range arr {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+		}
+	case TypeString:
+		if _, ok := value.(string); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected string, got %s", path, typeOf(value)))
+		}
+	case TypeInteger:
+		n, ok := value.(json.Number)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected integer, got %s", path, typeOf(value)))
+			return
+		}
+		if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: expected integer, got %s", path, n.String()))
+		}
+	case TypeNumber:
+		if _, ok := value.(json.Number); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected number, got %s", path, typeOf(value)))
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: expected boolean, got %s", path, typeOf(value)))
+		}
+	}
+}
+
+// fieldNames 獲取物件屬性名稱，優先使用宣告順序
+func (s *Schema) fieldNames() []string {
+	if len(s.Order) == len(s.Properties) {
+		return s.Order
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeOf 獲取 JSON 值的型別名稱
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}